@@ -1,9 +1,24 @@
 package main
 
 import (
-	"github.com/aanufriev/forum/internal/app/server"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/aanufriev/forum/internal/app/cli"
 )
 
 func main() {
-	server.StartApiServer()
+	err := cli.Run(os.Args[1:])
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, cli.ErrUsage):
+		if err != cli.ErrUsage {
+			log.Print(err)
+		}
+		os.Exit(2)
+	default:
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,335 @@
+package configs
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const envPrefix = "FORUM_"
+
+// Config holds everything the API server needs at runtime.
+type Config struct {
+	ListenAddr      string        `yaml:"listen_addr"`
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	HealthTimeout   time.Duration `yaml:"health_timeout"`
+	LogLevel        string        `yaml:"log_level"`
+	CORSOrigins     []string      `yaml:"cors_origins"`
+	AuthCompat      bool          `yaml:"auth_compat"`
+	SessionTTL      time.Duration `yaml:"session_ttl"`
+	RenameRedirect  time.Duration `yaml:"rename_redirect"`
+	ImportTimeout   time.Duration `yaml:"import_timeout"`
+
+	EmailVerification bool          `yaml:"email_verification"`
+	VerificationTTL   time.Duration `yaml:"verification_ttl"`
+	MailTransport     string        `yaml:"mail_transport"`
+	MailFrom          string        `yaml:"mail_from"`
+	MailOutbox        string        `yaml:"mail_outbox"`
+	SMTPAddr          string        `yaml:"smtp_addr"`
+	SMTPUsername      string        `yaml:"smtp_username"`
+	SMTPPassword      string        `yaml:"smtp_password"`
+}
+
+func Default() Config {
+	return Config{
+		ListenAddr:      ":5000",
+		DSN:             "host=localhost user=docker password=docker dbname=forum sslmode=disable",
+		MaxOpenConns:    100,
+		MaxIdleConns:    100,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		IdleTimeout:     time.Minute,
+		RequestTimeout:  5 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		HealthTimeout:   time.Second,
+		LogLevel:        "info",
+		SessionTTL:      30 * 24 * time.Hour,
+		RenameRedirect:  30 * 24 * time.Hour,
+		ImportTimeout:   5 * time.Minute,
+
+		EmailVerification: true,
+		VerificationTTL:   48 * time.Hour,
+		MailTransport:     "file",
+		MailFrom:          "forum@localhost",
+		MailOutbox:        "outbox",
+	}
+}
+
+// setting binds one Config field to a command line flag and to an
+// environment variable named FORUM_<NAME> (dashes replaced by underscores).
+type setting struct {
+	name  string
+	usage string
+	field func(cfg *Config) interface{}
+}
+
+var settings = []setting{
+	{"listen-addr", "address the API server listens on", func(c *Config) interface{} { return &c.ListenAddr }},
+	{"dsn", "postgres data source name", func(c *Config) interface{} { return &c.DSN }},
+	{"max-open-conns", "maximum number of open database connections (0 means unlimited)", func(c *Config) interface{} { return &c.MaxOpenConns }},
+	{"max-idle-conns", "maximum number of idle database connections", func(c *Config) interface{} { return &c.MaxIdleConns }},
+	{"conn-max-lifetime", "maximum time a database connection may be reused (0 means forever)", func(c *Config) interface{} { return &c.ConnMaxLifetime }},
+	{"conn-max-idle-time", "maximum time a database connection may stay idle (0 means forever)", func(c *Config) interface{} { return &c.ConnMaxIdleTime }},
+	{"read-timeout", "maximum duration for reading a whole request", func(c *Config) interface{} { return &c.ReadTimeout }},
+	{"write-timeout", "maximum duration for writing a response", func(c *Config) interface{} { return &c.WriteTimeout }},
+	{"idle-timeout", "maximum time to keep an idle keep-alive connection", func(c *Config) interface{} { return &c.IdleTimeout }},
+	{"request-timeout", "deadline for handling a single request", func(c *Config) interface{} { return &c.RequestTimeout }},
+	{"shutdown-timeout", "how long to wait for active requests on shutdown", func(c *Config) interface{} { return &c.ShutdownTimeout }},
+	{"health-timeout", "deadline for each readiness check", func(c *Config) interface{} { return &c.HealthTimeout }},
+	{"log-level", "log level: trace, debug, info, warn, error, fatal or panic", func(c *Config) interface{} { return &c.LogLevel }},
+	{"cors-origins", "comma-separated origins allowed to make cross-origin requests, * for any without credentials", func(c *Config) interface{} { return &c.CORSOrigins }},
+	{"auth-compat", "let requests without a session token act as the user named in the body", func(c *Config) interface{} { return &c.AuthCompat }},
+	{"session-ttl", "how long a session token issued by /api/auth/login stays valid", func(c *Config) interface{} { return &c.SessionTTL }},
+	{"rename-redirect", "how long an old nickname redirects to the new one after a rename (0 disables)", func(c *Config) interface{} { return &c.RenameRedirect }},
+	{"import-timeout", "deadline for handling a bulk user import", func(c *Config) interface{} { return &c.ImportTimeout }},
+	{"email-verification", "confirm email addresses by mail on registration and before an email change takes effect", func(c *Config) interface{} { return &c.EmailVerification }},
+	{"verification-ttl", "how long an email verification token stays valid", func(c *Config) interface{} { return &c.VerificationTTL }},
+	{"mail-transport", "how mail is delivered: file (into mail-outbox) or smtp", func(c *Config) interface{} { return &c.MailTransport }},
+	{"mail-from", "sender address of outgoing mail", func(c *Config) interface{} { return &c.MailFrom }},
+	{"mail-outbox", "maildir the file transport writes to", func(c *Config) interface{} { return &c.MailOutbox }},
+	{"smtp-addr", "host:port of the SMTP server for the smtp transport", func(c *Config) interface{} { return &c.SMTPAddr }},
+	{"smtp-username", "SMTP username, empty to send without authentication", func(c *Config) interface{} { return &c.SMTPUsername }},
+	{"smtp-password", "SMTP password", func(c *Config) interface{} { return &c.SMTPPassword }},
+}
+
+// stringList is a comma-separated flag value.
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = splitList(value)
+	return nil
+}
+
+func splitList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+// built-in defaults, the YAML file given by -config (or FORUM_CONFIG),
+// FORUM_* environment variables and command line flags.
+// The flags are registered on fs, so callers may add their own before calling Load.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	var (
+		fromFlags Config
+		path      string
+	)
+
+	fs.StringVar(&path, "config", os.Getenv(envPrefix+"CONFIG"), "path to a YAML config file")
+	for _, s := range settings {
+		switch ptr := s.field(&fromFlags).(type) {
+		case *string:
+			fs.StringVar(ptr, s.name, "", s.usage)
+		case *int:
+			fs.IntVar(ptr, s.name, 0, s.usage)
+		case *bool:
+			fs.BoolVar(ptr, s.name, false, s.usage)
+		case *time.Duration:
+			fs.DurationVar(ptr, s.name, 0, s.usage)
+		case *[]string:
+			fs.Var((*stringList)(ptr), s.name, s.usage)
+		}
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if path != "" {
+		err = cfg.loadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	err = cfg.loadEnv()
+	if err != nil {
+		return Config{}, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.name != f.Name {
+				continue
+			}
+
+			switch ptr := s.field(&cfg).(type) {
+			case *string:
+				*ptr = *s.field(&fromFlags).(*string)
+			case *int:
+				*ptr = *s.field(&fromFlags).(*int)
+			case *bool:
+				*ptr = *s.field(&fromFlags).(*bool)
+			case *time.Duration:
+				*ptr = *s.field(&fromFlags).(*time.Duration)
+			case *[]string:
+				*ptr = *s.field(&fromFlags).(*[]string)
+			}
+		}
+	})
+
+	err = cfg.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read config file '%v'. Error: %w", path, err)
+	}
+
+	err = yaml.UnmarshalStrict(data, c)
+	if err != nil {
+		return fmt.Errorf("couldn't parse config file '%v'. Error: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env())
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch ptr := s.field(c).(type) {
+		case *string:
+			*ptr = value
+		case *int:
+			*ptr, err = strconv.Atoi(value)
+		case *bool:
+			*ptr, err = strconv.ParseBool(value)
+		case *time.Duration:
+			*ptr, err = time.ParseDuration(value)
+		case *[]string:
+			*ptr = splitList(value)
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid value '%v' for %v. Error: %w", value, s.env(), err)
+		}
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var problems []string
+
+	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		problems = append(problems, fmt.Sprintf("listen-addr '%v' is not a host:port address", c.ListenAddr))
+	}
+
+	if c.DSN == "" {
+		problems = append(problems, "dsn must not be empty")
+	}
+
+	if c.MaxOpenConns < 0 {
+		problems = append(problems, "max-open-conns must not be negative")
+	}
+
+	if c.MaxIdleConns < 0 {
+		problems = append(problems, "max-idle-conns must not be negative")
+	}
+
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		problems = append(problems, "max-idle-conns must not exceed max-open-conns")
+	}
+
+	durations := map[string]time.Duration{
+		"conn-max-lifetime":  c.ConnMaxLifetime,
+		"conn-max-idle-time": c.ConnMaxIdleTime,
+		"read-timeout":       c.ReadTimeout,
+		"write-timeout":      c.WriteTimeout,
+		"idle-timeout":       c.IdleTimeout,
+		"request-timeout":    c.RequestTimeout,
+		"shutdown-timeout":   c.ShutdownTimeout,
+		"health-timeout":     c.HealthTimeout,
+		"session-ttl":        c.SessionTTL,
+		"rename-redirect":    c.RenameRedirect,
+		"import-timeout":     c.ImportTimeout,
+		"verification-ttl":   c.VerificationTTL,
+	}
+	for _, s := range settings {
+		if d, ok := durations[s.name]; ok && d < 0 {
+			problems = append(problems, fmt.Sprintf("%v must not be negative", s.name))
+		}
+	}
+
+	if c.SessionTTL == 0 {
+		problems = append(problems, "session-ttl must be positive")
+	}
+
+	if c.VerificationTTL == 0 {
+		problems = append(problems, "verification-ttl must be positive")
+	}
+
+	switch c.MailTransport {
+	case "file":
+		if c.MailOutbox == "" {
+			problems = append(problems, "mail-outbox must not be empty for the file transport")
+		}
+	case "smtp":
+		if _, _, err := net.SplitHostPort(c.SMTPAddr); err != nil {
+			problems = append(problems, fmt.Sprintf("smtp-addr '%v' is not a host:port address", c.SMTPAddr))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("mail-transport '%v' is neither file nor smtp", c.MailTransport))
+	}
+
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("log-level '%v' is unknown", c.LogLevel))
+	}
+
+	if len(problems) != 0 {
+		return fmt.Errorf("invalid config: %v", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
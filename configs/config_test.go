@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Unsetenv(key) })
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "forum.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+listen_addr: ":6000"
+max_open_conns: 200
+request_timeout: 3s
+log_level: debug
+`)
+	setenv(t, "FORUM_LISTEN_ADDR", ":7000")
+	setenv(t, "FORUM_REQUEST_TIMEOUT", "2s")
+	setenv(t, "FORUM_MAX_IDLE_CONNS", "50")
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{
+		"-config", path,
+		"-listen-addr", ":8000",
+		// Set explicitly, the zero value still wins over the environment.
+		"-max-idle-conns", "0",
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.ListenAddr != ":8000" {
+		t.Errorf("listen address = %q, want the flag's :8000", cfg.ListenAddr)
+	}
+	if cfg.MaxIdleConns != 0 {
+		t.Errorf("max idle conns = %v, want the flag's 0", cfg.MaxIdleConns)
+	}
+	if cfg.RequestTimeout != 2*time.Second {
+		t.Errorf("request timeout = %v, want the environment's 2s", cfg.RequestTimeout)
+	}
+	if cfg.MaxOpenConns != 200 || cfg.LogLevel != "debug" {
+		t.Errorf("max open conns = %v, log level = %q, want the file's 200 and debug", cfg.MaxOpenConns, cfg.LogLevel)
+	}
+	if cfg.ReadTimeout != Default().ReadTimeout {
+		t.Errorf("read timeout = %v, want the default %v", cfg.ReadTimeout, Default().ReadTimeout)
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	setenv(t, "FORUM_CONFIG", writeConfig(t, "listen_addr: \":6000\"\n"))
+
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ListenAddr != ":6000" {
+		t.Errorf("listen address = %q, want :6000 from the file in FORUM_CONFIG", cfg.ListenAddr)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  string
+		args []string
+		want string
+	}{
+		{name: "unknown key in file", file: "listen_adr: \":6000\"\n", want: "couldn't parse config file"},
+		{name: "malformed environment value", env: "many", want: "FORUM_MAX_OPEN_CONNS"},
+		{name: "unknown flag", args: []string{"-listen"}, want: "flag provided but not defined"},
+		{name: "invalid result", args: []string{"-dsn", ""}, want: "dsn must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeConfig(t, tt.file)}, args...)
+			}
+			if tt.env != "" {
+				setenv(t, "FORUM_MAX_OPEN_CONNS", tt.env)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			_, err := Load(fs, args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("defaults don't validate: %v", err)
+	}
+
+	unlimited := Default()
+	unlimited.MaxOpenConns = 0
+	unlimited.MaxIdleConns = 20
+	if err := unlimited.Validate(); err != nil {
+		t.Errorf("idle conns with unlimited open conns: %v", err)
+	}
+
+	broken := Default()
+	broken.ListenAddr = "localhost"
+	broken.DSN = ""
+	broken.MaxOpenConns = 10
+	broken.MaxIdleConns = 20
+	broken.WriteTimeout = -time.Second
+	broken.LogLevel = "loud"
+
+	err := broken.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil")
+	}
+
+	// Every problem is reported at once.
+	for _, problem := range []string{
+		"listen-addr 'localhost' is not a host:port address",
+		"dsn must not be empty",
+		"max-idle-conns must not exceed max-open-conns",
+		"write-timeout must not be negative",
+		"log-level 'loud' is unknown",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Validate() error = %v, want it to mention %q", err, problem)
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package configs
 
 const (
-	ApiPort                = ":5000"
-	ApiUrl                 = "/api"
-	Postgres               = "postgres"
-	DataSourceNamePostgres = "host=localhost user=docker password=docker dbname=forum sslmode=disable"
-	RequestID              = "reqID"
-	Limit                  = "limit"
-	Desc                   = "desc"
-	Since                  = "since"
-	Sort                   = "sort"
+	ApiUrl    = "/api"
+	Postgres  = "postgres"
+	RequestID = "reqID"
+	Actor     = "actor"
+	Limit     = "limit"
+	Desc      = "desc"
+	Since     = "since"
+	Sort      = "sort"
+	Query     = "q"
+	Match     = "match"
+	Forum     = "forum"
+	Viewer    = "viewer"
+	Subtree   = "subtree"
 )
@@ -1,15 +1,37 @@
 module github.com/aanufriev/forum
 
-go 1.15
+go 1.22
 
 require (
-	github.com/andybalholm/brotli v1.0.1 // indirect
 	github.com/buaazp/fasthttprouter v0.1.1
 	github.com/go-openapi/strfmt v0.19.11
-	github.com/klauspost/compress v1.11.7 // indirect
 	github.com/lib/pq v1.9.0
 	github.com/lithammer/shortuuid v3.0.0+incompatible
 	github.com/mailru/easyjson v0.7.6
+	github.com/prometheus/client_golang v1.22.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/valyala/fasthttp v1.19.0
+	golang.org/x/crypto v0.31.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.4 // indirect
+	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-openapi/errors v0.19.8 // indirect
+	github.com/go-stack/stack v1.8.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/josharian/intern v1.0.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/mitchellh/mapstructure v1.3.3 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	go.mongodb.org/mongo-driver v1.4.3 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
 )
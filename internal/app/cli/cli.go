@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/app/server"
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	authRepository "github.com/aanufriev/forum/internal/pkg/auth/repository"
+	authUsecase "github.com/aanufriev/forum/internal/pkg/auth/usecase"
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	forumRepository "github.com/aanufriev/forum/internal/pkg/forum/repository"
+	forumUsecase "github.com/aanufriev/forum/internal/pkg/forum/usecase"
+	"github.com/aanufriev/forum/internal/pkg/migrate"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	permissionRepository "github.com/aanufriev/forum/internal/pkg/permission/repository"
+	permissionUsecase "github.com/aanufriev/forum/internal/pkg/permission/usecase"
+	"github.com/aanufriev/forum/internal/pkg/user"
+	userRepository "github.com/aanufriev/forum/internal/pkg/user/repository"
+	userUsecase "github.com/aanufriev/forum/internal/pkg/user/usecase"
+
+	_ "github.com/lib/pq"
+)
+
+// ErrUsage is returned when a command is called with wrong arguments.
+// The usage has already been printed by then.
+var ErrUsage = fmt.Errorf("invalid usage")
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "start the API server (default when no command is given)", serve},
+		{"migrate", "apply, roll back or list schema migrations", migrateCmd},
+		{"stats", "show the number of users, forums, threads and posts", stats},
+		{"clear", "delete all data", clearCmd},
+		{"user", "create, show, rename, delete a user, set their password or role, or recompute reputation", userCmd},
+		{"forum", "create or show a forum", forumCmd},
+		{"thread", "show a thread", threadCmd},
+		{"help", "show this help", help},
+	}
+}
+
+// Run dispatches args (without the program name) to a command.
+// Arguments that start with a flag are passed to serve, so "./main -dsn ..." keeps working.
+func Run(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(args)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+
+	_ = help(nil)
+	return fmt.Errorf("unknown command '%v': %w", args[0], ErrUsage)
+}
+
+func help([]string) error {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: %v <command> [flags] [arguments]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-8v %v\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(out, "\nrun '%v <command> -h' for the flags of a command\n", os.Args[0])
+
+	return nil
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %v %v [flags] %v\n", os.Args[0], name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// usageError prints the usage of fs and returns ErrUsage.
+func usageError(fs *flag.FlagSet) error {
+	fs.Usage()
+	return ErrUsage
+}
+
+// app is what data commands operate on.
+type app struct {
+	cfg         configs.Config
+	db          *sql.DB
+	users       user.Usecase
+	forums      forum.Usecase
+	auth        auth.Usecase
+	permissions permission.Usecase
+	out         printer
+}
+
+// setup loads the configuration, connects to the database and builds the usecases.
+// It refuses to work on a database whose schema doesn't match the binary.
+func setup(fs *flag.FlagSet, args []string, nargs int) (*app, error) {
+	format := fs.String("output", formatTable, "output format: table or json")
+
+	cfg, err := configs.Load(fs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() != nargs {
+		return nil, usageError(fs)
+	}
+
+	out, err := newPrinter(*format, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := server.OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migrate.New(db)
+	if err == nil {
+		err = migrator.Check(context.Background())
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	userRepository, err := userRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	forumRepository, err := forumRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	authRepository, err := authRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	permissionRepository, err := permissionRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	mailer, err := server.NewMailer(cfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	permissions := permissionUsecase.New(permissionRepository, cfg.AuthCompat)
+
+	return &app{
+		cfg:         cfg,
+		db:          db,
+		users:       userUsecase.New(userRepository, cfg.RenameRedirect, mailer, cfg.VerificationTTL, permissions),
+		forums:      forumUsecase.New(forumRepository, permissions),
+		auth:        authUsecase.New(authRepository, cfg.SessionTTL),
+		permissions: permissions,
+		out:         out,
+	}, nil
+}
+
+func (a *app) close() {
+	_ = a.db.Close()
+}
+
+// context is bounded by request-timeout and canceled on SIGINT or SIGTERM.
+// Commands run as the operator, so permission checks always pass.
+func (a *app) context() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(permission.Operator(context.Background()), syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.RequestTimeout)
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+func serve(args []string) error {
+	fs := newFlagSet("serve", "")
+
+	cfg, err := configs.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return usageError(fs)
+	}
+
+	return server.StartApiServer(cfg)
+}
+
+func migrateCmd(args []string) error {
+	fs := newFlagSet("migrate", "up|down|status")
+	format := fs.String("output", formatTable, "output format of status: table or json")
+
+	cfg, err := configs.Load(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return usageError(fs)
+	}
+
+	out, err := newPrinter(*format, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	db, err := server.OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch fs.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%v\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Printf("schema is up to date at version %v\n", migrator.Latest())
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if errors.Is(err, migrate.ErrNothingToUndo) {
+			fmt.Println(err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("rolled back %04d_%v\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		return out.print(statuses, migrationsTable(statuses))
+	default:
+		return usageError(fs)
+	}
+
+	return nil
+}
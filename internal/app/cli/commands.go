@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+// action is the second word of "user create", "forum show" and so on.
+type action struct {
+	name string
+	run  func(args []string) error
+}
+
+func dispatch(name string, actions []action, args []string) error {
+	if len(args) != 0 {
+		for _, a := range actions {
+			if a.name == args[0] {
+				return a.run(args[1:])
+			}
+		}
+	}
+
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		names = append(names, a.name)
+	}
+	fmt.Fprintf(os.Stderr, "usage: %v %v %v [flags] [arguments]\n", os.Args[0], name, strings.Join(names, "|"))
+
+	return ErrUsage
+}
+
+func stats(args []string) error {
+	app, err := setup(newFlagSet("stats", ""), args, 0)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	info, err := app.forums.GetServiceInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(info, statsTable(info))
+}
+
+func clearCmd(args []string) error {
+	fs := newFlagSet("clear", "")
+	yes := fs.Bool("yes", false, "confirm that all data should be deleted")
+
+	app, err := setup(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	if !*yes {
+		return fmt.Errorf("refusing to delete all data without -yes: %w", ErrUsage)
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	return app.forums.ClearService(ctx)
+}
+
+func userCmd(args []string) error {
+	return dispatch("user", []action{
+		{"create", userCreate},
+		{"show", userShow},
+		{"rename", userRename},
+		{"delete", userDelete},
+		{"password", userPassword},
+		{"reputation", userReputation},
+		{"role", userRole},
+	}, args)
+}
+
+// readPassword reads one line from stdin, so passwords don't end up in ps or shell history.
+func readPassword() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("couldn't read password from stdin. Error: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func userPassword(args []string) error {
+	fs := newFlagSet("user password", "<nickname> < password")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	err = app.auth.SetPassword(ctx, fs.Arg(0), password)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("password of '%v' updated\n", fs.Arg(0))
+	return nil
+}
+
+func userRole(args []string) error {
+	fs := newFlagSet("user role", "<nickname> member|admin")
+
+	app, err := setup(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	err = app.permissions.SetRole(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("role of '%v' set to %v\n", fs.Arg(0), fs.Arg(1))
+	return nil
+}
+
+func userReputation(args []string) error {
+	app, err := setup(newFlagSet("user reputation", ""), args, 0)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	fixed, err := app.users.RecomputeReputation(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("reputation recomputed, %v users corrected\n", fixed)
+	return nil
+}
+
+func userCreate(args []string) error {
+	fs := newFlagSet("user create", "<nickname>")
+	fullname := fs.String("fullname", "", "full name")
+	email := fs.String("email", "", "email address (required)")
+	about := fs.String("about", "", "about the user")
+	withPassword := fs.Bool("password-stdin", false, "read the password from the first line of stdin")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	if *email == "" {
+		return usageError(fs)
+	}
+
+	var password string
+	if *withPassword {
+		password, err = readPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	profile := models.User{
+		Nickname: fs.Arg(0),
+		Fullname: fullname,
+		Email:    email,
+		About:    about,
+	}
+
+	err = app.users.Create(ctx, profile, password)
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(profile, usersTable(profile))
+}
+
+func userShow(args []string) error {
+	fs := newFlagSet("user show", "<nickname>")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	profile, err := app.users.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(profile, usersTable(profile))
+}
+
+func userRename(args []string) error {
+	fs := newFlagSet("user rename", "<nickname> <new nickname>")
+
+	app, err := setup(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	profile, err := app.users.Rename(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(profile, usersTable(profile))
+}
+
+func userDelete(args []string) error {
+	fs := newFlagSet("user delete", "<nickname>")
+	mode := fs.String("mode", user.DeleteModeAnonymize, "anonymize keeps the content under a tombstone identity, hard removes it")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	profile, err := app.users.Delete(ctx, fs.Arg(0), *mode)
+	if err != nil {
+		return err
+	}
+
+	if *mode == user.DeleteModeHard {
+		fmt.Printf("deleted user '%v'\n", fs.Arg(0))
+		return nil
+	}
+
+	return app.out.print(profile, usersTable(profile))
+}
+
+func forumCmd(args []string) error {
+	return dispatch("forum", []action{
+		{"create", forumCreate},
+		{"show", forumShow},
+	}, args)
+}
+
+func forumCreate(args []string) error {
+	fs := newFlagSet("forum create", "<slug>")
+	title := fs.String("title", "", "forum title (required)")
+	owner := fs.String("user", "", "nickname of the owner (required)")
+	parent := fs.String("parent", "", "slug of the parent forum")
+	visibility := fs.String("visibility", "", "public (the default), restricted or private")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	if *title == "" || *owner == "" {
+		return usageError(fs)
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	nickname, err := app.users.CheckIfUserExists(ctx, *owner)
+	if err != nil {
+		return err
+	}
+
+	model := models.Forum{
+		Slug:       fs.Arg(0),
+		Title:      *title,
+		User:       nickname,
+		Visibility: *visibility,
+	}
+	if *parent != "" {
+		model.Parent = parent
+	}
+
+	err = app.forums.Create(ctx, &model)
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(model, forumsTable(model))
+}
+
+func forumShow(args []string) error {
+	fs := newFlagSet("forum show", "<slug>")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	model, err := app.forums.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(model, forumsTable(model))
+}
+
+func threadCmd(args []string) error {
+	return dispatch("thread", []action{
+		{"show", threadShow},
+	}, args)
+}
+
+func threadShow(args []string) error {
+	fs := newFlagSet("thread show", "<slug_or_id>")
+
+	app, err := setup(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	defer app.close()
+
+	ctx, cancel := app.context()
+	defer cancel()
+
+	thread, err := app.forums.GetThread(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return app.out.print(thread, threadsTable(thread))
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/migrate"
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// printer writes either the JSON form of a value or its table form.
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	if format != formatTable && format != formatJSON {
+		return printer{}, fmt.Errorf("unknown output format '%v', want %v or %v: %w", format, formatTable, formatJSON, ErrUsage)
+	}
+
+	return printer{
+		format: format,
+		w:      w,
+	}, nil
+}
+
+func (p printer) print(value interface{}, t table) error {
+	if p.format == formatJSON {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(p.w, string(data))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func usersTable(users ...models.User) table {
+	t := table{header: []string{"NICKNAME", "FULLNAME", "EMAIL", "REPUTATION", "ABOUT"}}
+	for _, u := range users {
+		t.rows = append(t.rows, []string{u.Nickname, optional(u.Fullname), optional(u.Email), strconv.Itoa(u.Reputation), optional(u.About)})
+	}
+
+	return t
+}
+
+func forumsTable(forums ...models.Forum) table {
+	t := table{header: []string{"SLUG", "TITLE", "USER", "PARENT", "VISIBILITY", "THREADS", "POSTS"}}
+	for _, f := range forums {
+		t.rows = append(t.rows, []string{f.Slug, f.Title, f.User, optional(f.Parent), f.Visibility, strconv.Itoa(f.Threads), strconv.Itoa(f.Posts)})
+	}
+
+	return t
+}
+
+func threadsTable(threads ...models.Thread) table {
+	t := table{header: []string{"ID", "SLUG", "FORUM", "AUTHOR", "TITLE", "VOTES", "CREATED"}}
+	for _, th := range threads {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(th.ID), optional(th.Slug), th.Forum, th.Author, th.Title,
+			strconv.Itoa(th.Votes), th.Created.String(),
+		})
+	}
+
+	return t
+}
+
+func statsTable(info models.ServiceInfo) table {
+	return table{
+		header: []string{"USERS", "FORUMS", "THREADS", "POSTS"},
+		rows: [][]string{{
+			strconv.Itoa(info.User), strconv.Itoa(info.Forum), strconv.Itoa(info.Thread), strconv.Itoa(info.Post),
+		}},
+	}
+}
+
+func migrationsTable(statuses []migrate.Status) table {
+	t := table{header: []string{"VERSION", "NAME", "APPLIED"}}
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		t.rows = append(t.rows, []string{fmt.Sprintf("%04d", status.Version), status.Name, applied})
+	}
+
+	return t
+}
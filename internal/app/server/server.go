@@ -1,53 +1,162 @@
 package server
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	authDelivery "github.com/aanufriev/forum/internal/pkg/auth/delivery"
+	authRepository "github.com/aanufriev/forum/internal/pkg/auth/repository"
+	authUsecase "github.com/aanufriev/forum/internal/pkg/auth/usecase"
 	forumDelivery "github.com/aanufriev/forum/internal/pkg/forum/delivery"
 	forumRepository "github.com/aanufriev/forum/internal/pkg/forum/repository"
 	forumUsecase "github.com/aanufriev/forum/internal/pkg/forum/usecase"
+	"github.com/aanufriev/forum/internal/pkg/health"
+	"github.com/aanufriev/forum/internal/pkg/mail"
+	"github.com/aanufriev/forum/internal/pkg/metrics"
+	"github.com/aanufriev/forum/internal/pkg/middleware"
+	"github.com/aanufriev/forum/internal/pkg/migrate"
+	permissionDelivery "github.com/aanufriev/forum/internal/pkg/permission/delivery"
+	permissionRepository "github.com/aanufriev/forum/internal/pkg/permission/repository"
+	permissionUsecase "github.com/aanufriev/forum/internal/pkg/permission/usecase"
 	userDelivery "github.com/aanufriev/forum/internal/pkg/user/delivery"
 	userRepository "github.com/aanufriev/forum/internal/pkg/user/repository"
 	userUsecase "github.com/aanufriev/forum/internal/pkg/user/usecase"
-	"github.com/buaazp/fasthttprouter"
+	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 
 	_ "github.com/lib/pq"
 )
 
-func StartApiServer() {
-	db, err := sql.Open(configs.Postgres, configs.DataSourceNamePostgres)
+var ErrShutdownTimeout = fmt.Errorf("shutdown deadline exceeded")
+
+type Server struct {
+	cfg     configs.Config
+	db      *sql.DB
+	server  *fasthttp.Server
+	checker *health.Checker
+}
+
+func New(cfg configs.Config) (*Server, error) {
+	level, err := logrus.ParseLevel(cfg.LogLevel)
 	if err != nil {
-		log.Fatal(err)
-		return
+		return nil, err
 	}
-	err = db.Ping()
+	logrus.SetLevel(level)
+
+	db, err := OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	err = migrator.Check(context.Background())
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("refusing to start. Error: %w", err)
+	}
+
+	guard := auth.Guard{Compat: cfg.AuthCompat}
+	if guard.Compat {
+		log.Print("auth-compat is on: requests without a session token may act as any user")
+	}
+
+	authRepository, err := authRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	authUsecase := authUsecase.New(authRepository, cfg.SessionTTL)
+	authDelivery := authDelivery.New(authUsecase, cfg.RequestTimeout)
+
+	permissionRepository, err := permissionRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	permissionUsecase := permissionUsecase.New(permissionRepository, cfg.AuthCompat)
+	permissionDelivery := permissionDelivery.New(permissionUsecase, cfg.RequestTimeout)
+
+	userRepository, err := userRepository.New(context.Background(), db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	mailer, err := NewMailer(cfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	userUsecase := userUsecase.New(userRepository, cfg.RenameRedirect, mailer, cfg.VerificationTTL, permissionUsecase)
+	userDelivery := userDelivery.New(userUsecase, guard, cfg.RequestTimeout, cfg.ImportTimeout)
 
+	forumRepository, err := forumRepository.New(context.Background(), db)
 	if err != nil {
-		log.Fatal(err)
+		_ = db.Close()
+		return nil, err
 	}
+	forumUsecase := forumUsecase.New(forumRepository, permissionUsecase)
+	forumDelivery := forumDelivery.New(forumUsecase, userUsecase, guard, cfg.RequestTimeout)
+
+	metrics.RegisterDB(db)
 
-	userRepository := userRepository.New(db)
-	userUsecase := userUsecase.New(userRepository)
-	userDelivery := userDelivery.New(userUsecase)
+	checker := health.New(cfg.HealthTimeout)
+	checker.Add("database", health.DatabaseCheck(db))
+	checker.Add("schema", migrator.Check)
 
-	forumRepository := forumRepository.New(db)
-	forumUsecase := forumUsecase.New(forumRepository)
-	forumDelivery := forumDelivery.New(forumUsecase, userRepository)
+	router := metrics.NewRouter()
 
-	router := fasthttprouter.New()
+	router.POST("/api/auth/login", authDelivery.Login)
+	router.POST("/api/auth/logout", authDelivery.Logout)
 
 	router.POST("/api/user/:nickname/create", userDelivery.Create)
 	router.GET("/api/user/:nickname/profile", userDelivery.Get)
 	router.POST("/api/user/:nickname/profile", userDelivery.Update)
+	router.POST("/api/user/:nickname/rename", userDelivery.Rename)
+	if mailer != nil {
+		router.POST("/api/user/:nickname/verify", userDelivery.Verify)
+		router.POST("/api/user/:nickname/verify/resend", userDelivery.ResendVerification)
+	}
+	router.DELETE("/api/user/:nickname", userDelivery.Delete)
+	router.POST("/api/user/:nickname/ignore/:other", userDelivery.Ignore)
+	router.DELETE("/api/user/:nickname/ignore/:other", userDelivery.Unignore)
+	router.GET("/api/user/:nickname/threads", forumDelivery.GetUserThreads)
+	router.GET("/api/user/:nickname/posts", forumDelivery.GetUserPosts)
+	router.GET("/api/user/:nickname/votes", forumDelivery.GetUserVotes)
+	router.GET("/api/users", userDelivery.Search)
+	router.POST("/api/users/import", userDelivery.Import)
 
+	router.GET("/api/forums", forumDelivery.GetForums)
 	router.POST("/api/forum/:slug", forumDelivery.Create)
 	router.GET("/api/forum/:slug/details", forumDelivery.Get)
+	router.POST("/api/forum/:slug/details", forumDelivery.Update)
+	router.DELETE("/api/forum/:slug", forumDelivery.Delete)
+	router.GET("/api/forum/:slug/children", forumDelivery.GetChildren)
+	router.GET("/api/forum/:slug/settings", forumDelivery.GetSettings)
+	router.POST("/api/forum/:slug/settings", forumDelivery.UpdateSettings)
 	router.POST("/api/forum/:slug/create", forumDelivery.CreateThread)
 	router.GET("/api/forum/:slug/threads", forumDelivery.GetThreads)
 	router.GET("/api/forum/:slug/users", forumDelivery.GetUsersFromForum)
+	router.GET("/api/forum/:slug/moderators", permissionDelivery.GetModerators)
+	router.POST("/api/forum/:slug/moderators/:nickname", permissionDelivery.GrantModerator)
+	router.DELETE("/api/forum/:slug/moderators/:nickname", permissionDelivery.RevokeModerator)
+	router.GET("/api/forum/:slug/members", permissionDelivery.GetMembers)
+	router.POST("/api/forum/:slug/members/:nickname", permissionDelivery.AddMember)
+	router.DELETE("/api/forum/:slug/members/:nickname", permissionDelivery.RemoveMember)
 
 	router.POST("/api/thread/:slug_or_id/create", forumDelivery.CreatePosts)
 	router.GET("/api/thread/:slug_or_id/details", forumDelivery.GetThread)
@@ -61,6 +170,149 @@ func StartApiServer() {
 	router.POST("/api/service/clear", forumDelivery.ClearService)
 	router.GET("/api/service/status", forumDelivery.GetServiceInfo)
 
-	log.Printf("server started at port %v", configs.ApiPort)
-	log.Fatal(fasthttp.ListenAndServe(":5000", router.Handler))
+	router.Router.GET("/metrics", metrics.Handler())
+	router.Router.GET("/healthz", checker.Liveness)
+	router.Router.GET("/readyz", checker.Readiness)
+
+	return &Server{
+		cfg:     cfg,
+		db:      db,
+		checker: checker,
+		server: &fasthttp.Server{
+			Handler: middleware.Chain(
+				router.Handler,
+				middleware.RequestID,
+				// Outside the rest, so that panics anywhere answer 500 with the CORS headers already set.
+				middleware.Recover,
+				middleware.AccessLog,
+				middleware.CORS(cfg.CORSOrigins),
+				middleware.Authenticate(authUsecase.Authenticate, cfg.RequestTimeout),
+			),
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+	}, nil
+}
+
+// OpenDB connects to postgres with the configured pool limits.
+func OpenDB(cfg configs.Config) (*sql.DB, error) {
+	db, err := sql.Open(configs.Postgres, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open database. Error: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	err = db.Ping()
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("couldn't connect to database. Error: %w", err)
+	}
+
+	return db, nil
+}
+
+// NewMailer returns the configured transport, or nil when email verification is off.
+func NewMailer(cfg configs.Config) (mail.Mailer, error) {
+	if !cfg.EmailVerification {
+		return nil, nil
+	}
+
+	if cfg.MailTransport == mail.TransportSMTP {
+		return mail.NewSMTP(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+
+	return mail.NewOutbox(cfg.MailOutbox, cfg.MailFrom)
+}
+
+// Run listens on the configured address and serves until ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		_ = s.db.Close()
+		return fmt.Errorf("couldn't listen on %v. Error: %w", s.cfg.ListenAddr, err)
+	}
+
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is done, then stops accepting,
+// waits up to ShutdownTimeout for active requests and closes the database pool.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.server.Serve(ln)
+	}()
+
+	log.Printf("server started at %v", ln.Addr())
+
+	select {
+	case err := <-serveErr:
+		_ = s.db.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, draining active requests for up to %v", s.cfg.ShutdownTimeout)
+	return s.shutdown()
+}
+
+func (s *Server) shutdown() error {
+	s.checker.SetShuttingDown()
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- s.server.Shutdown()
+	}()
+
+	timer := time.NewTimer(s.cfg.ShutdownTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err = <-drained:
+	case <-timer.C:
+		err = ErrShutdownTimeout
+	}
+
+	dbErr := s.db.Close()
+	if err != nil {
+		return err
+	}
+
+	return dbErr
+}
+
+// StartApiServer serves until the process receives SIGINT or SIGTERM.
+func StartApiServer(cfg configs.Config) error {
+	server, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Printf("received %v", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = server.Run(ctx)
+	if errors.Is(err, ErrShutdownTimeout) {
+		log.Printf("some requests were still running after %v", cfg.ShutdownTimeout)
+	}
+
+	return err
 }
@@ -0,0 +1,44 @@
+package apperror
+
+import (
+	"fmt"
+)
+
+// Kinds every domain error belongs to. The delivery layer maps them to HTTP statuses.
+var (
+	ErrNotFound   = fmt.Errorf("not found")
+	ErrConflict   = fmt.Errorf("conflict")
+	ErrValidation = fmt.Errorf("validation failed")
+	ErrForbidden  = fmt.Errorf("forbidden")
+	// ErrUnauthorized means the caller didn't prove who they are.
+	ErrUnauthorized = fmt.Errorf("unauthorized")
+)
+
+var (
+	ErrInvalidBody  = New(ErrValidation, "invalid_body", "request body is malformed")
+	ErrInvalidParam = New(ErrValidation, "invalid_param", "query parameter is invalid")
+)
+
+// Error is a domain error with a machine-readable code.
+// Wrap it with fmt.Errorf("...: %w", err) to add details.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func New(kind error, code string, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
@@ -0,0 +1,32 @@
+package apperror
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgQueryCanceled       = "57014"
+)
+
+func IsUniqueViolation(err error) bool {
+	return hasPgCode(err, pgUniqueViolation)
+}
+
+func IsForeignKeyViolation(err error) bool {
+	return hasPgCode(err, pgForeignKeyViolation)
+}
+
+// IsQueryCanceled reports whether postgres aborted a statement,
+// which happens when the request context is canceled or times out.
+func IsQueryCanceled(err error) bool {
+	return hasPgCode(err, pgQueryCanceled)
+}
+
+func hasPgCode(err error, code pq.ErrorCode) bool {
+	var pgErr *pq.Error
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,65 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/response"
+	"github.com/valyala/fasthttp"
+)
+
+type AuthDelivery struct {
+	authUsecase auth.Usecase
+	timeout     time.Duration
+}
+
+func New(authUsecase auth.Usecase, timeout time.Duration) AuthDelivery {
+	return AuthDelivery{
+		authUsecase: authUsecase,
+		timeout:     timeout,
+	}
+}
+
+func (a AuthDelivery) Login(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, a.timeout)
+	defer cancel()
+
+	var credentials models.Credentials
+	err := credentials.UnmarshalJSON(ctx.PostBody())
+	if err != nil || credentials.Nickname == "" {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+
+	session, err := a.authUsecase.Login(reqCtx, credentials)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, session)
+}
+
+// Logout revokes the token the request was made with.
+func (a AuthDelivery) Logout(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, a.timeout)
+	defer cancel()
+
+	token, ok := auth.BearerToken(string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)))
+	if !ok {
+		response.Error(ctx, auth.ErrUnauthenticated)
+		return
+	}
+
+	err := a.authUsecase.Logout(reqCtx, token)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+)
+
+// Guard decides which user a request acts as.
+type Guard struct {
+	// Compat lets requests without a session token act as whoever they name,
+	// the way the API worked before authentication existed.
+	Compat bool
+}
+
+// Actor returns the nickname a request acts as. claimed is the user named in the
+// request body (author, nickname, ...); it may be left empty when a token is sent.
+func (g Guard) Actor(ctx context.Context, claimed string) (string, error) {
+	actor, ok := requestctx.Actor(ctx)
+	if !ok {
+		if g.Compat {
+			return claimed, nil
+		}
+		return "", ErrUnauthenticated
+	}
+
+	if claimed != "" && !strings.EqualFold(claimed, actor) {
+		return "", fmt.Errorf("session belongs to '%v', request names '%v': %w", actor, claimed, ErrActorMismatch)
+	}
+
+	return actor, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header value.
+func BearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[len(prefix):]), true
+}
@@ -0,0 +1,24 @@
+package auth
+
+import "testing"
+
+func TestBearerToken(t *testing.T) {
+	valid := map[string]string{
+		"Bearer abc":     "abc",
+		"bearer abc":     "abc",
+		"BEARER abc":     "abc",
+		"Bearer  abc \t": "abc",
+	}
+	for header, want := range valid {
+		got, ok := BearerToken(header)
+		if !ok || got != want {
+			t.Errorf("BearerToken(%q) = %q, %v, want %q, true", header, got, ok, want)
+		}
+	}
+
+	for _, header := range []string{"", "Bearer", "Bearer ", "Basic YWxpY2U6c2VjcmV0", "abc"} {
+		if got, ok := BearerToken(header); ok {
+			t.Errorf("BearerToken(%q) = %q, true, want no token", header, got)
+		}
+	}
+}
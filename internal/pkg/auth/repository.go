@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+)
+
+var (
+	ErrInvalidCredentials = apperror.New(apperror.ErrUnauthorized, "invalid_credentials", "nickname or password is wrong")
+	ErrInvalidToken       = apperror.New(apperror.ErrUnauthorized, "invalid_token", "session token is invalid or expired")
+	ErrUnauthenticated    = apperror.New(apperror.ErrUnauthorized, "unauthenticated", "this request needs a session token")
+	ErrActorMismatch      = apperror.New(apperror.ErrForbidden, "actor_mismatch", "request names another user than the session")
+	ErrWeakPassword       = apperror.New(apperror.ErrValidation, "weak_password", "password must be 8 to 72 bytes long")
+	ErrPasswordRequired   = apperror.New(apperror.ErrValidation, "password_required", "password is required")
+)
+
+type Repository interface {
+	// GetPasswordHash returns the user's id, canonical nickname and password hash.
+	// The hash is empty for users who never set a password.
+	GetPasswordHash(ctx context.Context, nickname string) (int, string, string, error)
+	SetPasswordHash(ctx context.Context, nickname string, hash string) error
+	CreateSession(ctx context.Context, userID int, tokenHash []byte, expires time.Time) error
+	GetSessionNickname(ctx context.Context, tokenHash []byte) (string, error)
+	DeleteSession(ctx context.Context, tokenHash []byte) error
+	DeleteExpiredSessions(ctx context.Context) error
+}
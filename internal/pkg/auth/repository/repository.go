@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/metrics"
+	"github.com/aanufriev/forum/internal/pkg/statements"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+type AuthRepository struct {
+	stmts authStatements
+}
+
+type authStatements struct {
+	getPasswordHash       *sql.Stmt
+	setPasswordHash       *sql.Stmt
+	createSession         *sql.Stmt
+	getSessionNickname    *sql.Stmt
+	deleteSession         *sql.Stmt
+	deleteExpiredSessions *sql.Stmt
+}
+
+// New prepares the repository's statements, so the schema must already be migrated.
+func New(ctx context.Context, db *sql.DB) (auth.Repository, error) {
+	var s authStatements
+	err := statements.Prepare(
+		ctx, db,
+		statements.Statement{Dest: &s.getPasswordHash, Query: "SELECT id, nickname, COALESCE(password_hash, '') FROM users WHERE nickname = $1"},
+		statements.Statement{Dest: &s.setPasswordHash, Query: "UPDATE users SET password_hash = $2 WHERE nickname = $1"},
+		statements.Statement{Dest: &s.createSession, Query: "INSERT INTO sessions (token_hash, user_id, expires) VALUES ($1, $2, $3)"},
+		statements.Statement{Dest: &s.getSessionNickname, Query: `SELECT u.nickname FROM sessions AS s
+		JOIN users AS u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires > now()`},
+		statements.Statement{Dest: &s.deleteSession, Query: "DELETE FROM sessions WHERE token_hash = $1"},
+		statements.Statement{Dest: &s.deleteExpiredSessions, Query: "DELETE FROM sessions WHERE expires <= now()"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return AuthRepository{
+		stmts: s,
+	}, nil
+}
+
+func (a AuthRepository) GetPasswordHash(ctx context.Context, nickname string) (int, string, string, error) {
+	defer metrics.ObserveQuery("auth", "GetPasswordHash", time.Now())
+
+	var (
+		id   int
+		hash string
+	)
+	err := a.stmts.getPasswordHash.QueryRowContext(
+		ctx,
+		nickname,
+	).Scan(&id, &nickname, &hash)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+		}
+		return 0, "", "", fmt.Errorf("couldn't get password of user '%v'. Error: %w", nickname, err)
+	}
+
+	return id, nickname, hash, nil
+}
+
+func (a AuthRepository) SetPasswordHash(ctx context.Context, nickname string, hash string) error {
+	defer metrics.ObserveQuery("auth", "SetPasswordHash", time.Now())
+
+	result, err := a.stmts.setPasswordHash.ExecContext(
+		ctx,
+		nickname, hash,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't set password of user '%v'. Error: %w", nickname, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+	}
+
+	return nil
+}
+
+func (a AuthRepository) CreateSession(ctx context.Context, userID int, tokenHash []byte, expires time.Time) error {
+	defer metrics.ObserveQuery("auth", "CreateSession", time.Now())
+
+	_, err := a.stmts.createSession.ExecContext(
+		ctx,
+		tokenHash, userID, expires,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't create session. Error: %w", err)
+	}
+
+	return nil
+}
+
+func (a AuthRepository) GetSessionNickname(ctx context.Context, tokenHash []byte) (string, error) {
+	defer metrics.ObserveQuery("auth", "GetSessionNickname", time.Now())
+
+	var nickname string
+	err := a.stmts.getSessionNickname.QueryRowContext(
+		ctx,
+		tokenHash,
+	).Scan(&nickname)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", auth.ErrInvalidToken
+		}
+		return "", fmt.Errorf("couldn't get session. Error: %w", err)
+	}
+
+	return nickname, nil
+}
+
+func (a AuthRepository) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	defer metrics.ObserveQuery("auth", "DeleteSession", time.Now())
+
+	_, err := a.stmts.deleteSession.ExecContext(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("couldn't delete session. Error: %w", err)
+	}
+
+	return nil
+}
+
+func (a AuthRepository) DeleteExpiredSessions(ctx context.Context) error {
+	defer metrics.ObserveQuery("auth", "DeleteExpiredSessions", time.Now())
+
+	_, err := a.stmts.deleteExpiredSessions.ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't delete expired sessions. Error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+const tokenBytes = 32
+
+// NewToken returns a random URL-safe token. Only HashToken of it is stored,
+// so a leaked table doesn't leak usable tokens.
+func NewToken() (string, error) {
+	raw := make([]byte, tokenBytes)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", fmt.Errorf("couldn't generate token. Error: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
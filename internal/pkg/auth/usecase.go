@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+type Usecase interface {
+	Login(ctx context.Context, credentials models.Credentials) (models.Session, error)
+	Logout(ctx context.Context, token string) error
+	// Authenticate returns the nickname the session token belongs to.
+	Authenticate(ctx context.Context, token string) (string, error)
+	SetPassword(ctx context.Context, nickname string, password string) error
+}
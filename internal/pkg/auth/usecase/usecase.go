@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/user"
+	"github.com/go-openapi/strfmt"
+)
+
+// dummyHash is a bcrypt hash at the default cost that no password matches in practice.
+// Login checks against it when there is no real hash, so it takes as long either way.
+const dummyHash = "$2a$10$Q9hQE.rj/JM4me1pW1JlL.xmDKn5n.QS0u3CQhlt194huNzAjDeHO"
+
+type AuthUsecase struct {
+	authRepository auth.Repository
+	sessionTTL     time.Duration
+}
+
+func New(authRepository auth.Repository, sessionTTL time.Duration) auth.Usecase {
+	return AuthUsecase{
+		authRepository: authRepository,
+		sessionTTL:     sessionTTL,
+	}
+}
+
+// Login doesn't tell a missing user or one without a password from a wrong password,
+// neither by the answer nor by how long it takes.
+func (a AuthUsecase) Login(ctx context.Context, credentials models.Credentials) (models.Session, error) {
+	id, nickname, hash, err := a.authRepository.GetPasswordHash(ctx, credentials.Nickname)
+	if err != nil && !errors.Is(err, user.ErrUserDoesntExists) {
+		return models.Session{}, err
+	}
+
+	if err != nil || hash == "" {
+		auth.CheckPassword(dummyHash, credentials.Password)
+		return models.Session{}, auth.ErrInvalidCredentials
+	}
+
+	if !auth.CheckPassword(hash, credentials.Password) {
+		return models.Session{}, auth.ErrInvalidCredentials
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	err = a.authRepository.DeleteExpiredSessions(ctx)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	expires := time.Now().Add(a.sessionTTL)
+	err = a.authRepository.CreateSession(ctx, id, auth.HashToken(token), expires)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	return models.Session{
+		Token:    token,
+		Nickname: nickname,
+		Expires:  strfmt.DateTime(expires),
+	}, nil
+}
+
+func (a AuthUsecase) Logout(ctx context.Context, token string) error {
+	return a.authRepository.DeleteSession(ctx, auth.HashToken(token))
+}
+
+func (a AuthUsecase) Authenticate(ctx context.Context, token string) (string, error) {
+	return a.authRepository.GetSessionNickname(ctx, auth.HashToken(token))
+}
+
+func (a AuthUsecase) SetPassword(ctx context.Context, nickname string, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return a.authRepository.SetPasswordHash(ctx, nickname, hash)
+}
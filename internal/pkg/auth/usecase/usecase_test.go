@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/user"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordless knows bob, who has no password, and nobody else.
+type passwordless struct {
+	auth.Repository
+}
+
+func (passwordless) GetPasswordHash(ctx context.Context, nickname string) (int, string, string, error) {
+	if nickname == "bob" {
+		return 1, "bob", "", nil
+	}
+
+	return 0, "", "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+}
+
+func TestLoginWithoutHash(t *testing.T) {
+	// Costs differing would make missing users answer faster or slower than real ones.
+	cost, err := bcrypt.Cost([]byte(dummyHash))
+	if err != nil || cost != bcrypt.DefaultCost {
+		t.Fatalf("dummy hash cost = %v, %v, want %v", cost, err, bcrypt.DefaultCost)
+	}
+
+	usecase := New(passwordless{}, time.Hour)
+	for _, nickname := range []string{"bob", "alice"} {
+		_, err := usecase.Login(context.Background(), models.Credentials{Nickname: nickname, Password: "not a password"})
+		if !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("Login(%v) error = %v, want %v", nickname, err, auth.ErrInvalidCredentials)
+		}
+	}
+}
@@ -1,15 +1,22 @@
 package delivery
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/auth"
 	"github.com/aanufriev/forum/internal/pkg/forum"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/response"
 	"github.com/aanufriev/forum/internal/pkg/user"
 	"github.com/valyala/fasthttp"
 )
@@ -17,282 +24,388 @@ import (
 type ForumDelivery struct {
 	forumUsecase forum.Usecase
 	userUsecase  user.Usecase
+	guard        auth.Guard
+	timeout      time.Duration
 }
 
-func New(forumUsecase forum.Usecase, userUsecase user.Usecase) ForumDelivery {
+func New(forumUsecase forum.Usecase, userUsecase user.Usecase, guard auth.Guard, timeout time.Duration) ForumDelivery {
 	return ForumDelivery{
 		forumUsecase: forumUsecase,
 		userUsecase:  userUsecase,
+		guard:        guard,
+		timeout:      timeout,
 	}
 }
 
 func (f ForumDelivery) Create(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
-	var forum models.Forum
-	err := json.Unmarshal(ctx.PostBody(), &forum)
+	var model models.Forum
+	err := json.Unmarshal(ctx.PostBody(), &model)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 
-	nickname, err := f.userUsecase.CheckIfUserExists(forum.User)
+	model.User, err = f.guard.Actor(reqCtx, model.User)
 	if err != nil {
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find user with id #%v\n", forum.User),
+		response.Error(ctx, err)
+		return
+	}
+
+	nickname, err := f.userUsecase.CheckIfUserExists(reqCtx, model.User)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+	model.User = nickname
+
+	err = f.forumUsecase.Create(reqCtx, &model)
+	if err != nil {
+		if !errors.Is(err, forum.ErrDataConflict) {
+			response.Error(ctx, err)
+			return
 		}
 
-		ctx.SetStatusCode(http.StatusNotFound)
-		err = json.NewEncoder(ctx).Encode(msg)
+		existingForum, err := f.forumUsecase.Get(reqCtx, model.Slug)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, err)
 			return
 		}
+
+		response.JSON(ctx, http.StatusConflict, existingForum)
 		return
 	}
-	forum.User = nickname
 
-	err = f.forumUsecase.Create(forum)
+	response.JSON(ctx, http.StatusCreated, model)
+}
+
+func (f ForumDelivery) Get(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	subtree := string(ctx.QueryArgs().Peek(configs.Subtree)) == "true"
+
+	model, err := f.forumUsecase.GetDetails(reqCtx, slug, subtree)
 	if err != nil {
-		existingForum, err := f.forumUsecase.Get(forum.Slug)
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, model)
+}
+
+func (f ForumDelivery) GetForums(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	args := ctx.QueryArgs()
+	params := forum.ListParams{
+		Query: string(args.Peek(configs.Query)),
+		Sort:  string(args.Peek(configs.Sort)),
+		Since: string(args.Peek(configs.Since)),
+	}
+
+	limitParam := string(args.Peek(configs.Limit))
+	if limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, fmt.Errorf("limit '%v' is not a number: %w", limitParam, apperror.ErrInvalidParam))
 			return
 		}
+		params.Limit = limit
+	}
+
+	descParam := string(args.Peek(configs.Desc))
+	switch descParam {
+	case "", "false":
+	case "true":
+		params.Desc = true
+	default:
+		response.Error(ctx, fmt.Errorf("desc '%v' is neither 'true' nor 'false': %w", descParam, apperror.ErrInvalidParam))
+		return
+	}
+
+	forums, err := f.forumUsecase.GetForums(reqCtx, params)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, forums)
+}
+
+func (f ForumDelivery) GetChildren(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	subtree := string(ctx.QueryArgs().Peek(configs.Subtree)) == "true"
+
+	forums, err := f.forumUsecase.GetChildren(reqCtx, slug, subtree)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, forums)
+}
+
+func (f ForumDelivery) Update(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+
+	var model models.Forum
+	err := json.Unmarshal(ctx.PostBody(), &model)
+	if err != nil {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+	// Only the title, the owner, the parent and the visibility can change.
+	model = models.Forum{
+		Title:      model.Title,
+		User:       model.User,
+		Parent:     model.Parent,
+		Visibility: model.Visibility,
+	}
 
-		ctx.SetStatusCode(http.StatusConflict)
-		err = json.NewEncoder(ctx).Encode(existingForum)
+	if model.User != "" {
+		model.User, err = f.userUsecase.CheckIfUserExists(reqCtx, model.User)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, err)
 			return
 		}
+	}
+
+	if model.Title == "" && model.User == "" && model.Parent == nil && model.Visibility == "" {
+		model, err = f.forumUsecase.GetDetails(reqCtx, slug, false)
+	} else {
+		model, err = f.forumUsecase.Update(reqCtx, slug, model)
+	}
+
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 
-	ctx.SetStatusCode(http.StatusCreated)
-	err = json.NewEncoder(ctx).Encode(forum)
+	response.JSON(ctx, http.StatusOK, model)
+}
+
+func (f ForumDelivery) Delete(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+
+	deletion, err := f.forumUsecase.Delete(reqCtx, slug)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, deletion)
 }
 
-func (f ForumDelivery) Get(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+func (f ForumDelivery) GetSettings(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slug := ctx.UserValue("slug").(string)
-	forum, err := f.forumUsecase.Get(slug)
+
+	settings, err := f.forumUsecase.GetSettings(reqCtx, slug)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find forum with slug: %v", slug),
-		}
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, settings)
+}
+
+func (f ForumDelivery) UpdateSettings(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+
+	settings, err := f.forumUsecase.GetSettings(reqCtx, slug)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
 
-		_ = json.NewEncoder(ctx).Encode(msg)
+	// Fields missing from the body keep their current values.
+	err = json.Unmarshal(ctx.PostBody(), &settings)
+	if err != nil {
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(forum)
+	settings, err = f.forumUsecase.UpdateSettings(reqCtx, slug, settings)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, settings)
 }
 
 func (f ForumDelivery) CreateThread(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slug := ctx.UserValue("slug").(string)
 
 	thread := &models.Thread{}
 	err := json.Unmarshal(ctx.PostBody(), thread)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 	thread.Forum = slug
 
-	nickname, err := f.userUsecase.CheckIfUserExists(thread.Author)
+	thread.Author, err = f.guard.Actor(reqCtx, thread.Author)
 	if err != nil {
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find user with id #%v\n", thread.Author),
-		}
+		response.Error(ctx, err)
+		return
+	}
 
-		ctx.SetStatusCode(http.StatusNotFound)
-		err = json.NewEncoder(ctx).Encode(msg)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
-			return
-		}
+	nickname, err := f.userUsecase.CheckIfUserExists(reqCtx, thread.Author)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 	thread.Author = nickname
 
-	err = f.forumUsecase.CreateThread(thread)
+	err = f.forumUsecase.CreateThread(reqCtx, thread)
 	if err != nil {
-		if err == forum.ErrForumDoesntExists {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find thread forum by slug: %v", thread.Forum),
-			}
-
-			_ = json.NewEncoder(ctx).Encode(msg)
+		if !errors.Is(err, forum.ErrDataConflict) {
+			response.Error(ctx, err)
 			return
 		}
 
-		existedThread, err := f.forumUsecase.GetThread(*thread.Slug)
+		existedThread, err := f.forumUsecase.GetThread(reqCtx, *thread.Slug)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, err)
 			return
 		}
 
-		ctx.SetStatusCode(http.StatusConflict)
-		_ = json.NewEncoder(ctx).Encode(existedThread)
+		response.JSON(ctx, http.StatusConflict, existedThread)
 		return
 	}
 
-	ctx.SetStatusCode(http.StatusCreated)
-	err = json.NewEncoder(ctx).Encode(thread)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
-	}
+	response.JSON(ctx, http.StatusCreated, thread)
 }
 
 func (f ForumDelivery) GetThreads(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slug := ctx.UserValue("slug").(string)
 
-	_, err := f.forumUsecase.CheckForum(slug)
+	_, err := f.forumUsecase.CheckForum(reqCtx, slug)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find forum by slug: %v", slug),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
 	limit := string(ctx.URI().QueryArgs().Peek(configs.Limit))
 	desc := string(ctx.URI().QueryArgs().Peek(configs.Desc))
 	since := string(ctx.URI().QueryArgs().Peek(configs.Since))
-
-	threads, err := f.forumUsecase.GetThreads(slug, limit, since, desc)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
+	viewer := string(ctx.URI().QueryArgs().Peek(configs.Viewer))
+	if viewer != "" {
+		// Someone else's ignore list isn't for others to probe.
+		viewer, err = f.guard.Actor(reqCtx, viewer)
+		if err != nil {
+			response.Error(ctx, err)
+			return
+		}
 	}
 
-	err = json.NewEncoder(ctx).Encode(threads)
+	threads, err := f.forumUsecase.GetThreads(reqCtx, slug, limit, since, desc, viewer)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, threads)
 }
 
 func (f ForumDelivery) CreatePosts(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slugOrID := ctx.UserValue("slug_or_id").(string)
 
-	thread, err := f.forumUsecase.GetThreadIDAndForum(slugOrID)
+	thread, err := f.forumUsecase.GetThreadIDAndForum(reqCtx, slugOrID)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find post thread by id: %v", slugOrID),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
 	posts := make([]models.Post, 0)
 	err = json.Unmarshal(ctx.PostBody(), &posts)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 
 	if len(posts) == 0 {
-		ctx.SetStatusCode(http.StatusCreated)
-		_ = json.NewEncoder(ctx).Encode(posts)
+		response.JSON(ctx, http.StatusCreated, posts)
 		return
 	}
 
-	err = f.forumUsecase.CreatePosts(thread, posts)
-	if err != nil {
-		if err == forum.ErrWrongParent {
-			ctx.SetStatusCode(http.StatusConflict)
-			msg := models.Message{
-				Text: "Parent post was created in another thread",
-			}
-
-			_ = json.NewEncoder(ctx).Encode(msg)
+	for i := range posts {
+		posts[i].Author, err = f.guard.Actor(reqCtx, posts[i].Author)
+		if err != nil {
+			response.Error(ctx, err)
 			return
 		}
-		for _, post := range posts {
-			_, err = f.userUsecase.CheckIfUserExists(post.Author)
-			if err != nil {
-				msg := models.Message{
-					Text: fmt.Sprintf("Can't find post author by nickname: %v", post.Author),
-				}
-
-				ctx.SetStatusCode(http.StatusNotFound)
-				err = json.NewEncoder(ctx).Encode(msg)
-				if err != nil {
-					ctx.SetStatusCode(http.StatusInternalServerError)
-					return
-				}
-				return
-			}
-		}
-		ctx.SetStatusCode(http.StatusConflict)
-		msg := models.Message{
-			Text: "Parent post was created in another thread",
-		}
+	}
 
-		_ = json.NewEncoder(ctx).Encode(msg)
+	err = f.forumUsecase.CreatePosts(reqCtx, thread, posts)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 
-	ctx.SetStatusCode(http.StatusCreated)
-	_ = json.NewEncoder(ctx).Encode(posts)
+	response.JSON(ctx, http.StatusCreated, posts)
 }
 
 func (f ForumDelivery) GetThread(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slugOrID := ctx.UserValue("slug_or_id").(string)
 
-	threads, err := f.forumUsecase.GetThread(slugOrID)
+	thread, err := f.forumUsecase.GetThread(reqCtx, slugOrID)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find thread by slug: %v", slugOrID),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(threads)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
-	}
+	response.JSON(ctx, http.StatusOK, thread)
 }
 
 func (f ForumDelivery) Vote(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slugOrID := ctx.UserValue("slug_or_id").(string)
 
 	vote := models.Vote{}
 	err := json.Unmarshal(ctx.PostBody(), &vote)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+
+	vote.Nickname, err = f.guard.Actor(reqCtx, vote.Nickname)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 
@@ -303,44 +416,31 @@ func (f ForumDelivery) Vote(ctx *fasthttp.RequestCtx) {
 	}
 	vote.ID = id
 
-	thread, err := f.forumUsecase.Vote(vote)
+	thread, err := f.forumUsecase.Vote(reqCtx, vote)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find thread by slug: %v", slugOrID),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(thread)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
-	}
+	response.JSON(ctx, http.StatusOK, thread)
 }
 
 func (f ForumDelivery) GetPosts(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slugOrID := ctx.UserValue("slug_or_id").(string)
 
-	err := f.forumUsecase.CheckThread(slugOrID)
+	err := f.forumUsecase.CheckThread(reqCtx, slugOrID)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find thread by slug: %v", slugOrID),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
 	limitParam := string(ctx.URI().QueryArgs().Peek(configs.Limit))
 	limit, err := strconv.Atoi(limitParam)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, fmt.Errorf("limit '%v' is not a number: %w", limitParam, apperror.ErrInvalidParam))
 		return
 	}
 
@@ -351,84 +451,73 @@ func (f ForumDelivery) GetPosts(ctx *fasthttp.RequestCtx) {
 	}
 
 	sinceParam := string(ctx.URI().QueryArgs().Peek(configs.Since))
-
-	posts, err := f.forumUsecase.GetPosts(slugOrID, limit, sortParam, descParam, sinceParam)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
+	viewerParam := string(ctx.URI().QueryArgs().Peek(configs.Viewer))
+	if viewerParam != "" {
+		viewerParam, err = f.guard.Actor(reqCtx, viewerParam)
+		if err != nil {
+			response.Error(ctx, err)
+			return
+		}
 	}
 
-	err = json.NewEncoder(ctx).Encode(posts)
+	posts, err := f.forumUsecase.GetPosts(reqCtx, slugOrID, limit, sortParam, descParam, sinceParam, viewerParam)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, posts)
 }
 
 func (f ForumDelivery) UpdateThread(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slugOrID := ctx.UserValue("slug_or_id").(string)
 
-	err := f.forumUsecase.CheckThread(slugOrID)
+	err := f.forumUsecase.CheckThread(reqCtx, slugOrID)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find thread by slug: %v", slugOrID),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
 	thread := models.Thread{}
 	err = json.Unmarshal(ctx.PostBody(), &thread)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 
 	if thread.Title == "" && thread.Message == "" {
-		thread, err = f.forumUsecase.GetThread(slugOrID)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusBadRequest)
-			return
-		}
+		thread, err = f.forumUsecase.GetThread(reqCtx, slugOrID)
 	} else {
-		thread, err = f.forumUsecase.UpdateThread(slugOrID, thread)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
-			return
-		}
+		thread, err = f.forumUsecase.UpdateThread(reqCtx, slugOrID, thread)
 	}
 
-	err = json.NewEncoder(ctx).Encode(thread)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, thread)
 }
 
 func (f ForumDelivery) GetUsersFromForum(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	slug := ctx.UserValue("slug").(string)
 
-	_, err := f.forumUsecase.CheckForum(slug)
+	_, err := f.forumUsecase.CheckForum(reqCtx, slug)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find forum by slug: %v", slug),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
 	limitParam := string(ctx.URI().QueryArgs().Peek(configs.Limit))
 	limit, err := strconv.Atoi(limitParam)
 	if err != nil && limitParam != "" {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, fmt.Errorf("limit '%v' is not a number: %w", limitParam, apperror.ErrInvalidParam))
 		return
 	}
 
@@ -439,32 +528,24 @@ func (f ForumDelivery) GetUsersFromForum(ctx *fasthttp.RequestCtx) {
 
 	sinceParam := string(ctx.URI().QueryArgs().Peek(configs.Since))
 
-	users, err := f.forumUsecase.GetUsersFromForum(slug, limit, sinceParam, descParam)
+	users, err := f.forumUsecase.GetUsersFromForum(reqCtx, slug, limit, sinceParam, descParam)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(users)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
-	}
+	response.JSON(ctx, http.StatusOK, users)
 }
 
 func (f ForumDelivery) GetPostDetails(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	id := ctx.UserValue("id").(string)
 
-	post, err := f.forumUsecase.GetPostDetails(id)
+	post, err := f.forumUsecase.GetPostDetails(reqCtx, id)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find post with id: %v", id),
-		}
-
-		_ = json.NewEncoder(ctx).Encode(msg)
+		response.Error(ctx, err)
 		return
 	}
 
@@ -474,135 +555,178 @@ func (f ForumDelivery) GetPostDetails(ctx *fasthttp.RequestCtx) {
 
 	related := string(ctx.URI().QueryArgs().Peek("related"))
 	if strings.Contains(related, "user") {
-		author, err := f.userUsecase.Get(post.Author)
+		author, err := f.userUsecase.Get(reqCtx, post.Author)
 		if err != nil {
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find user with id #%v\n", post.Author),
-			}
-
-			ctx.SetStatusCode(http.StatusNotFound)
-			err = json.NewEncoder(ctx).Encode(msg)
-			if err != nil {
-				ctx.SetStatusCode(http.StatusInternalServerError)
-				return
-			}
+			response.Error(ctx, err)
 			return
 		}
 		postInfo.Author = &author
 	}
 
 	if strings.Contains(related, "thread") {
-		thread, err := f.forumUsecase.GetThread(strconv.Itoa(post.Thread))
+		thread, err := f.forumUsecase.GetThread(reqCtx, strconv.Itoa(post.Thread))
 		if err != nil {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find thread forum by slug: %v", post.Thread),
-			}
-
-			_ = json.NewEncoder(ctx).Encode(msg)
+			response.Error(ctx, err)
 			return
 		}
 		postInfo.Thread = &thread
 	}
 
 	if strings.Contains(related, "forum") {
-		forum, err := f.forumUsecase.Get(post.Forum)
+		model, err := f.forumUsecase.Get(reqCtx, post.Forum)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find forum by slug: %v", post.Forum),
-			}
-
-			_ = json.NewEncoder(ctx).Encode(msg)
+			response.Error(ctx, err)
 			return
 		}
-		postInfo.Forum = &forum
+		postInfo.Forum = &model
 	}
 
-	err = json.NewEncoder(ctx).Encode(postInfo)
-	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
-		return
-	}
+	response.JSON(ctx, http.StatusOK, postInfo)
 }
 
 func (f ForumDelivery) UpdatePost(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
 	id := ctx.UserValue("id").(string)
 
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, fmt.Errorf("post id '%v' is not a number: %w", id, apperror.ErrInvalidParam))
 		return
 	}
 
 	var post models.Post
 	err = json.Unmarshal(ctx.PostBody(), &post)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
 		return
 	}
 
 	if post.Message == "" {
-		post, err := f.forumUsecase.GetPostDetails(id)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find post with id: %v", id),
-			}
+		post, err = f.forumUsecase.GetPostDetails(reqCtx, id)
+	} else {
+		post.ID = idInt
+		post, err = f.forumUsecase.UpdatePost(reqCtx, post)
+	}
 
-			_ = json.NewEncoder(ctx).Encode(msg)
-			return
-		}
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
 
-		err = json.NewEncoder(ctx).Encode(post)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
-			return
-		}
+	response.JSON(ctx, http.StatusOK, post)
+}
+
+func (f ForumDelivery) ClearService(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	err := f.forumUsecase.ClearService(reqCtx)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+}
+
+func (f ForumDelivery) GetServiceInfo(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	info, err := f.forumUsecase.GetServiceInfo(reqCtx)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
-	post.ID = idInt
 
-	post, err = f.forumUsecase.UpdatePost(post)
+	response.JSON(ctx, http.StatusOK, info)
+}
+
+// activityParams reads the query of the per-user listings and checks that
+// the user and the optional forum exist.
+func (f ForumDelivery) activityParams(reqCtx context.Context, ctx *fasthttp.RequestCtx) (forum.ActivityParams, error) {
+	args := ctx.QueryArgs()
+	params := forum.ActivityParams{
+		Forum: string(args.Peek(configs.Forum)),
+		Since: string(args.Peek(configs.Since)),
+		Desc:  string(args.Peek(configs.Desc)) == "true",
+	}
+
+	limitParam := string(args.Peek(configs.Limit))
+	limit, err := strconv.Atoi(limitParam)
+	if (err != nil && limitParam != "") || limit < 0 {
+		return params, fmt.Errorf("limit '%v' is not a number: %w", limitParam, apperror.ErrInvalidParam)
+	}
+	params.Limit = limit
+
+	params.Nickname, err = f.userUsecase.CheckIfUserExists(reqCtx, ctx.UserValue("nickname").(string))
 	if err != nil {
-		ctx.SetStatusCode(http.StatusNotFound)
-		msg := models.Message{
-			Text: fmt.Sprintf("Can't find post with id: %v", id),
+		return params, err
+	}
+
+	if params.Forum != "" {
+		params.Forum, err = f.forumUsecase.CheckForum(reqCtx, params.Forum)
+		if err != nil {
+			return params, err
 		}
+	}
+
+	return params, nil
+}
 
-		_ = json.NewEncoder(ctx).Encode(msg)
+func (f ForumDelivery) GetUserThreads(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	params, err := f.activityParams(reqCtx, ctx)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(post)
+	threads, err := f.forumUsecase.GetUserThreads(reqCtx, params)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, threads)
 }
 
-func (f ForumDelivery) ClearService(ctx *fasthttp.RequestCtx) {
-	err := f.forumUsecase.ClearService()
+func (f ForumDelivery) GetUserPosts(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
+
+	params, err := f.activityParams(reqCtx, ctx)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	posts, err := f.forumUsecase.GetUserPosts(reqCtx, params)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, posts)
 }
 
-func (f ForumDelivery) GetServiceInfo(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+func (f ForumDelivery) GetUserVotes(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, f.timeout)
+	defer cancel()
 
-	info, err := f.forumUsecase.GetServiceInfo()
+	params, err := f.activityParams(reqCtx, ctx)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(info)
+	votes, err := f.forumUsecase.GetUserVotes(reqCtx, params)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	response.JSON(ctx, http.StatusOK, votes)
 }
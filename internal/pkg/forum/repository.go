@@ -1,37 +1,127 @@
 package forum
 
 import (
-	"fmt"
+	"context"
+	"time"
 
+	"github.com/aanufriev/forum/internal/pkg/apperror"
 	"github.com/aanufriev/forum/internal/pkg/models"
 )
 
 var (
-	ErrForumDoesntExists = fmt.Errorf("forum not exists")
-	ErrDataConflict      = fmt.Errorf("data conflict")
-	ErrWrongParent       = fmt.Errorf("wrong parent")
+	ErrForumDoesntExists  = apperror.New(apperror.ErrNotFound, "forum_not_found", "forum doesn't exist")
+	ErrThreadDoesntExists = apperror.New(apperror.ErrNotFound, "thread_not_found", "thread doesn't exist")
+	ErrPostDoesntExists   = apperror.New(apperror.ErrNotFound, "post_not_found", "post doesn't exist")
+	ErrDataConflict       = apperror.New(apperror.ErrConflict, "forum_conflict", "data conflicts with existing forum data")
+	ErrWrongParent        = apperror.New(apperror.ErrConflict, "wrong_parent", "parent post was created in another thread")
+	ErrInvalidVoice       = apperror.New(apperror.ErrValidation, "invalid_voice", "voice must be 1 or -1")
+	ErrForumCycle         = apperror.New(apperror.ErrConflict, "forum_cycle", "a forum can't be moved below itself or its subforums")
+	ErrForumHasChildren   = apperror.New(apperror.ErrConflict, "forum_has_children", "forum has subforums, move or delete them first")
+	ErrInvalidVisibility  = apperror.New(apperror.ErrValidation, "invalid_visibility", "visibility must be public, restricted or private")
+	ErrInvalidSettings    = apperror.New(apperror.ErrValidation, "invalid_settings", "limits of forum settings can't be negative")
+	ErrPostTooLong        = apperror.New(apperror.ErrValidation, "post_too_long", "message is longer than the forum allows")
+	ErrPostTooSoon        = apperror.New(apperror.ErrValidation, "post_too_soon", "the forum makes authors wait between posts")
+	ErrVotesDisabled      = apperror.New(apperror.ErrValidation, "votes_disabled", "the forum doesn't take votes")
+	ErrOwnerThreadsOnly   = apperror.New(apperror.ErrValidation, "owner_threads_only", "only the forum owner can start threads in this forum")
+	ErrNestedReplies      = apperror.New(apperror.ErrValidation, "nested_replies_disabled", "the forum doesn't take replies to posts")
+	ErrReplyTooDeep       = apperror.New(apperror.ErrValidation, "reply_too_deep", "reply is nested deeper than the forum allows")
 )
 
+// DefaultSettings apply to forums whose owner hasn't changed them.
+var DefaultSettings = models.ForumSettings{
+	AllowVotes:    true,
+	NestedReplies: true,
+}
+
+// Visibilities of a forum. Restricted forums only take threads, posts and
+// votes from members; private forums don't show to non-members at all.
+const (
+	VisibilityPublic     = "public"
+	VisibilityRestricted = "restricted"
+	VisibilityPrivate    = "private"
+)
+
+// Orders of ListParams.
+const (
+	SortTitle    = "title"
+	SortCreated  = "created"
+	SortPosts    = "posts"
+	SortThreads  = "threads"
+	SortActivity = "activity"
+)
+
+// ListParams describe one page of GET /api/forums.
+type ListParams struct {
+	// Query matches anywhere in the title or the slug when set.
+	Query string
+	Sort  string
+	// Since is the last slug of the previous page.
+	Since string
+	Limit int
+	Desc  bool
+}
+
+// ActivityParams describe one page of a user's threads, posts or votes.
+type ActivityParams struct {
+	Nickname string
+	// Forum limits the page to one forum when set.
+	Forum string
+	// Since is a creation date for threads, a post id for posts and a thread id for votes.
+	Since string
+	Limit int
+	Desc  bool
+	// Hidden lists forums whose content is left out.
+	Hidden []string
+}
+
 type Repository interface {
-	Create(forum models.Forum) error
-	Get(slug string) (models.Forum, error)
-	CreateThread(model *models.Thread) error
-	CheckForum(slug string) (string, error)
-	GetThreads(slug string, limit string, since string, desc string) ([]models.Thread, error)
-	CreatePosts(thread models.Thread, posts []models.Post) error
-	GetThreadByID(id int) (models.Thread, error)
-	GetThreadBySlug(slug string) (models.Thread, error)
-	Vote(vote models.Vote) (models.Thread, error)
-	GetPosts(slugOrID string, limit int, order string, since string) ([]models.Post, error)
-	GetPostsTree(slugOrID string, limit int, order string, since string) ([]models.Post, error)
-	GetPostsParentTree(slugOrID string, limit int, order string, since string) ([]models.Post, error)
-	UpdateThread(thread models.Thread) (models.Thread, error)
-	GetUsersFromForum(slug string, limit int, since string, desc string) ([]models.User, error)
-	GetPostDetails(id string) (models.Post, error)
-	UpdatePost(post models.Post) (models.Post, error)
-	ClearService() error
-	GetServiceInfo() (models.ServiceInfo, error)
-	CheckThreadByID(id int) (int, error)
-	CheckThreadBySlug(slug string) (int, error)
-	GetThreadIDAndForum(slugOrID string) (models.Thread, error)
+	Create(ctx context.Context, forum models.Forum) error
+	Get(ctx context.Context, slug string) (models.Forum, error)
+	// Update keeps the title, owner or visibility of the forum when they are empty.
+	// A non-nil parent moves the forum below it, or to the top level if it is empty,
+	// in the same transaction.
+	Update(ctx context.Context, forum models.Forum) (models.Forum, error)
+	// Delete removes the forum with its threads, posts, votes and participants.
+	// It fails while the forum has subforums.
+	Delete(ctx context.Context, slug string) (models.ForumDeletion, error)
+	// GetBreadcrumb lists the forum's ancestors from the top level down.
+	GetBreadcrumb(ctx context.Context, slug string) ([]models.ForumLink, error)
+	// GetSubtreeCounters sums threads and posts of the forum and all its subforums but the hidden ones.
+	GetSubtreeCounters(ctx context.Context, slug string, hidden []string) (threads int, posts int, err error)
+	// GetForums lists forums in params.Sort order, ties broken by slug.
+	GetForums(ctx context.Context, params ListParams) ([]models.Forum, error)
+	// GetChildren lists the direct subforums by slug, with counters of their subtrees if subtree is set.
+	GetChildren(ctx context.Context, slug string, subtree bool, hidden []string) ([]models.Forum, error)
+	CreateThread(ctx context.Context, model *models.Thread) error
+	CheckForum(ctx context.Context, slug string) (string, error)
+	// GetThreads and GetPosts leave out authors viewer ignores unless viewer is empty.
+	GetThreads(ctx context.Context, slug string, limit string, since string, desc string, viewer string) ([]models.Thread, error)
+	// CreatePosts refuses the batch with ErrPostTooSoon if any author posted in the forum
+	// within interval, checking under a per-author lock so concurrent batches can't both pass.
+	CreatePosts(ctx context.Context, thread models.Thread, posts []models.Post, interval time.Duration) error
+	GetThreadByID(ctx context.Context, id int) (models.Thread, error)
+	GetThreadBySlug(ctx context.Context, slug string) (models.Thread, error)
+	Vote(ctx context.Context, vote models.Vote) (models.Thread, error)
+	GetPosts(ctx context.Context, slugOrID string, limit int, order string, since string, viewer string) ([]models.Post, error)
+	GetPostsTree(ctx context.Context, slugOrID string, limit int, order string, since string) ([]models.Post, error)
+	GetPostsParentTree(ctx context.Context, slugOrID string, limit int, order string, since string) ([]models.Post, error)
+	UpdateThread(ctx context.Context, thread models.Thread) (models.Thread, error)
+	GetUsersFromForum(ctx context.Context, slug string, limit int, since string, desc string) ([]models.User, error)
+	GetPostDetails(ctx context.Context, id string) (models.Post, error)
+	UpdatePost(ctx context.Context, post models.Post) (models.Post, error)
+	ClearService(ctx context.Context) error
+	GetServiceInfo(ctx context.Context) (models.ServiceInfo, error)
+	CheckThreadByID(ctx context.Context, id int) (int, error)
+	CheckThreadBySlug(ctx context.Context, slug string) (int, error)
+	GetThreadIDAndForum(ctx context.Context, slugOrID string) (models.Thread, error)
+	GetUserThreads(ctx context.Context, params ActivityParams) ([]models.Thread, error)
+	GetUserPosts(ctx context.Context, params ActivityParams) ([]models.Post, error)
+	GetUserVotes(ctx context.Context, params ActivityParams) ([]models.ThreadVote, error)
+	GetIgnoredAuthors(ctx context.Context, viewer string) ([]string, error)
+	GetPrivateForums(ctx context.Context) ([]string, error)
+	// GetSettings returns DefaultSettings for forums that have none stored.
+	GetSettings(ctx context.Context, slug string) (models.ForumSettings, error)
+	SetSettings(ctx context.Context, slug string, settings models.ForumSettings) error
+	// GetReplyDepth is how many posts a reply to the deepest of parents in the thread would be below.
+	GetReplyDepth(ctx context.Context, thread int, parents []int) (int, error)
 }
@@ -1,120 +1,693 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 
+	"github.com/aanufriev/forum/internal/pkg/apperror"
 	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/metrics"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/statements"
+	"github.com/aanufriev/forum/internal/pkg/user"
 	"github.com/go-openapi/strfmt"
+	"github.com/lib/pq"
 )
 
 type ForumRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts forumStatements
 }
 
-func New(db *sql.DB) forum.Repository {
-	return ForumRepository{
-		db: db,
+type forumStatements struct {
+	create              *sql.Stmt
+	get                 *sql.Stmt
+	update              *sql.Stmt
+	lockForum           *sql.Stmt
+	countForumVotes     *sql.Stmt
+	deleteForumPosts    *sql.Stmt
+	deleteForumThreads  *sql.Stmt
+	deleteForumUsers    *sql.Stmt
+	deleteForum         *sql.Stmt
+	hasChildren         *sql.Stmt
+	isAncestor          *sql.Stmt
+	setParent           *sql.Stmt
+	breadcrumb          *sql.Stmt
+	subtreeCounters     *sql.Stmt
+	children            *sql.Stmt
+	childrenSubtree     *sql.Stmt
+	forumsByTitle       statements.Ordered
+	forumsByCreated     statements.Ordered
+	forumsByPosts       statements.Ordered
+	forumsByThreads     statements.Ordered
+	forumsByActivity    statements.Ordered
+	createThread        *sql.Stmt
+	checkForum          *sql.Stmt
+	getThreads          statements.Ordered
+	getThreadsSince     statements.Ordered
+	viewThreads         statements.Ordered
+	viewThreadsSince    statements.Ordered
+	countParents        *sql.Stmt
+	createPosts         *sql.Stmt
+	getThreadByID       *sql.Stmt
+	getThreadBySlug     *sql.Stmt
+	getVote             *sql.Stmt
+	createVote          *sql.Stmt
+	updateVote          *sql.Stmt
+	getPosts            statements.Ordered
+	getPostsSince       statements.Ordered
+	viewPosts           statements.Ordered
+	viewPostsSince      statements.Ordered
+	getPostsTree        statements.Ordered
+	getPostsTreeSince   statements.Ordered
+	getPostsParent      statements.Ordered
+	getPostsParentSince statements.Ordered
+	updateThread        *sql.Stmt
+	getUsers            statements.Ordered
+	getUsersSince       statements.Ordered
+	getPost             *sql.Stmt
+	updatePost          *sql.Stmt
+	clear               *sql.Stmt
+	serviceInfo         *sql.Stmt
+	checkThreadByID     *sql.Stmt
+	checkThreadBySlug   *sql.Stmt
+	getThreadForum      *sql.Stmt
+	getThreadIDAndForum *sql.Stmt
+	ignoredAuthors      *sql.Stmt
+	userThreads         statements.Ordered
+	userThreadsSince    statements.Ordered
+	userPosts           statements.Ordered
+	userPostsSince      statements.Ordered
+	userVotes           statements.Ordered
+	userVotesSince      statements.Ordered
+	getSettings         *sql.Stmt
+	privateForums       *sql.Stmt
+	setSettings         *sql.Stmt
+	lockAuthors         *sql.Stmt
+	lastPosted          *sql.Stmt
+	replyDepth          *sql.Stmt
+}
+
+const (
+	selectForum  = "SELECT slug, title, user_nickname, parent, thread_count, post_count, visibility, created, last_activity FROM forums"
+	selectThread = "SELECT author, created, forum, id, msg, slug, title, votes FROM threads"
+	selectPost   = "SELECT author, created, forum, id, msg, parent, thread FROM posts"
+	selectUser   = `SELECT u.about, u.email, u.fullname, u.nickname, u.reputation, u.verified FROM users AS u
+	JOIN forum_user AS fu ON u.nickname = fu.nickname`
+	selectVote = `SELECT v.vote, t.author, t.created, t.forum, t.id, t.msg, t.slug, t.title, t.votes FROM thread_vote AS v
+	JOIN threads AS t ON t.id = v.thread_id`
+
+	// Appended to listings seen by a viewer, whose nickname is the last parameter but the limit.
+	notIgnored = ` AND author NOT IN (SELECT i.nickname FROM user_ignore AS ig
+	JOIN users AS i ON i.id = ig.ignored_id
+	JOIN users AS v ON v.id = ig.user_id
+	WHERE v.nickname = $%d)`
+
+	// The subforums of $1 at any depth, each with the direct child of $1 it is below.
+	subforums = `WITH RECURSIVE tree(root, slug, thread_count, post_count) AS (
+		SELECT slug, slug, thread_count, post_count FROM forums WHERE parent = $1
+		UNION ALL
+		SELECT t.root, f.slug, f.thread_count, f.post_count FROM tree AS t
+		JOIN forums AS f ON f.parent = t.slug
+	)`
+
+	// Listings across forums leave private forums and their content out.
+	notPrivate = "visibility <> 'private'"
+
+	// A page of GET /api/forums ordered by %[1]v, then slug. $1 (pattern) and $2 (since) may be NULL.
+	listForums = selectForum + `
+	WHERE ` + notPrivate + ` AND ($1::text IS NULL OR title ILIKE $1 OR slug ILIKE $1)
+	AND ($2::citext IS NULL OR (%[1]v, slug) %[2]v (SELECT %[1]v, slug FROM forums WHERE slug = $2))
+	ORDER BY %[1]v %[3]v, slug %[3]v LIMIT $3`
+
+	// The forum filters of the per-user listings: $2 may be NULL, $3 lists forums to leave out.
+	byAuthor = " WHERE author = $1 AND ($2::citext IS NULL OR forum = $2) AND forum <> ALL($3::citext[])"
+	byVoter  = " WHERE v.nickname = $1 AND ($2::citext IS NULL OR t.forum = $2) AND t.forum <> ALL($3::citext[])"
+)
+
+// hierarchyLockID serialises changes to forum parents, so concurrent moves can't form a cycle.
+// It is taken before any forum row, so moves and deletions can't deadlock.
+const hierarchyLockID = 7355609
+
+// New prepares the repository's statements, so the schema must already be migrated.
+// Queries that only differ in sort direction are prepared in both variants.
+func New(ctx context.Context, db *sql.DB) (forum.Repository, error) {
+	var s forumStatements
+	err := statements.Prepare(
+		ctx, db,
+		statements.Statement{Dest: &s.create, Query: "INSERT INTO forums (slug, title, user_nickname, parent, visibility) VALUES($1, $2, $3, $4, $5)"},
+		statements.Statement{Dest: &s.get, Query: selectForum + " WHERE slug = $1"},
+		statements.Statement{Dest: &s.update, Query: `UPDATE forums
+		SET title = COALESCE(NULLIF($2, ''), title), user_nickname = COALESCE(NULLIF($3, '')::citext, user_nickname),
+		visibility = COALESCE(NULLIF($4, ''), visibility)
+		WHERE slug = $1
+		RETURNING slug, title, user_nickname, parent, thread_count, post_count, visibility, created, last_activity`},
+		statements.Statement{Dest: &s.lockForum, Query: "SELECT slug FROM forums WHERE slug = $1 FOR UPDATE"},
+		statements.Statement{Dest: &s.countForumVotes, Query: `SELECT count(*) FROM thread_vote
+		WHERE thread_id IN (SELECT id FROM threads WHERE forum = $1)`},
+		statements.Statement{Dest: &s.deleteForumPosts, Query: "DELETE FROM posts WHERE thread IN (SELECT id FROM threads WHERE forum = $1)"},
+		// Votes go with their threads, whose trigger takes them back from the authors' reputation.
+		statements.Statement{Dest: &s.deleteForumThreads, Query: "DELETE FROM threads WHERE forum = $1"},
+		statements.Statement{Dest: &s.deleteForumUsers, Query: "DELETE FROM forum_user WHERE forum_slug = $1"},
+		statements.Statement{Dest: &s.deleteForum, Query: "DELETE FROM forums WHERE slug = $1"},
+
+		statements.Statement{Dest: &s.hasChildren, Query: "SELECT EXISTS(SELECT 1 FROM forums WHERE parent = $1)"},
+		// Whether $1 is $2 or one of its ancestors.
+		statements.Statement{Dest: &s.isAncestor, Query: `WITH RECURSIVE ancestors(slug, parent) AS (
+			SELECT slug, parent FROM forums WHERE slug = $2
+			UNION ALL
+			SELECT f.slug, f.parent FROM ancestors AS a
+			JOIN forums AS f ON f.slug = a.parent
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors WHERE slug = $1)`},
+		statements.Statement{Dest: &s.setParent, Query: "UPDATE forums SET parent = NULLIF($2, '')::citext WHERE slug = $1"},
+		statements.Statement{Dest: &s.breadcrumb, Query: `WITH RECURSIVE ancestors(slug, title, parent, depth) AS (
+			SELECT p.slug, p.title, p.parent, 1 FROM forums AS f
+			JOIN forums AS p ON p.slug = f.parent
+			WHERE f.slug = $1
+			UNION ALL
+			SELECT p.slug, p.title, p.parent, a.depth + 1 FROM ancestors AS a
+			JOIN forums AS p ON p.slug = a.parent
+		)
+		SELECT slug, title FROM ancestors ORDER BY depth DESC`},
+		statements.Statement{Dest: &s.subtreeCounters, Query: subforums + `
+		SELECT thread_count + COALESCE((SELECT sum(thread_count) FROM tree WHERE slug <> ALL($2::citext[])), 0),
+		post_count + COALESCE((SELECT sum(post_count) FROM tree WHERE slug <> ALL($2::citext[])), 0)
+		FROM forums WHERE slug = $1`},
+		statements.Statement{Dest: &s.children, Query: selectForum + " WHERE parent = $1 AND " + notPrivate + " ORDER BY slug"},
+		statements.Statement{Dest: &s.childrenSubtree, Query: subforums + `
+		SELECT c.slug, c.title, c.user_nickname, c.parent,
+		sum(t.thread_count) FILTER (WHERE t.slug <> ALL($2::citext[])),
+		sum(t.post_count) FILTER (WHERE t.slug <> ALL($2::citext[])),
+		c.visibility, c.created, c.last_activity
+		FROM tree AS t JOIN forums AS c ON c.slug = t.root
+		WHERE c.` + notPrivate + `
+		GROUP BY c.id ORDER BY c.slug`},
+
+		statements.Statement{Dest: &s.forumsByTitle.Asc, Query: fmt.Sprintf(listForums, "title", ">", "ASC")},
+		statements.Statement{Dest: &s.forumsByTitle.Desc, Query: fmt.Sprintf(listForums, "title", "<", "DESC")},
+		statements.Statement{Dest: &s.forumsByCreated.Asc, Query: fmt.Sprintf(listForums, "created", ">", "ASC")},
+		statements.Statement{Dest: &s.forumsByCreated.Desc, Query: fmt.Sprintf(listForums, "created", "<", "DESC")},
+		statements.Statement{Dest: &s.forumsByPosts.Asc, Query: fmt.Sprintf(listForums, "post_count", ">", "ASC")},
+		statements.Statement{Dest: &s.forumsByPosts.Desc, Query: fmt.Sprintf(listForums, "post_count", "<", "DESC")},
+		statements.Statement{Dest: &s.forumsByThreads.Asc, Query: fmt.Sprintf(listForums, "thread_count", ">", "ASC")},
+		statements.Statement{Dest: &s.forumsByThreads.Desc, Query: fmt.Sprintf(listForums, "thread_count", "<", "DESC")},
+		statements.Statement{Dest: &s.forumsByActivity.Asc, Query: fmt.Sprintf(listForums, "last_activity", ">", "ASC")},
+		statements.Statement{Dest: &s.forumsByActivity.Desc, Query: fmt.Sprintf(listForums, "last_activity", "<", "DESC")},
+
+		statements.Statement{Dest: &s.createThread, Query: `INSERT INTO threads (author, created, forum, msg, title, slug)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`},
+		statements.Statement{Dest: &s.checkForum, Query: "SELECT slug FROM forums WHERE slug = $1"},
+
+		statements.Statement{Dest: &s.getThreads.Asc, Query: selectThread + " WHERE forum = $1 ORDER BY created ASC LIMIT $2"},
+		statements.Statement{Dest: &s.getThreads.Desc, Query: selectThread + " WHERE forum = $1 ORDER BY created DESC LIMIT $2"},
+		statements.Statement{Dest: &s.getThreadsSince.Asc, Query: selectThread + " WHERE forum = $1 AND created >= $2 ORDER BY created ASC LIMIT $3"},
+		statements.Statement{Dest: &s.getThreadsSince.Desc, Query: selectThread + " WHERE forum = $1 AND created <= $2 ORDER BY created DESC LIMIT $3"},
+
+		statements.Statement{Dest: &s.viewThreads.Asc, Query: selectThread + " WHERE forum = $1" + fmt.Sprintf(notIgnored, 3) + " ORDER BY created ASC LIMIT $2"},
+		statements.Statement{Dest: &s.viewThreads.Desc, Query: selectThread + " WHERE forum = $1" + fmt.Sprintf(notIgnored, 3) + " ORDER BY created DESC LIMIT $2"},
+		statements.Statement{Dest: &s.viewThreadsSince.Asc, Query: selectThread + " WHERE forum = $1 AND created >= $2" + fmt.Sprintf(notIgnored, 4) + " ORDER BY created ASC LIMIT $3"},
+		statements.Statement{Dest: &s.viewThreadsSince.Desc, Query: selectThread + " WHERE forum = $1 AND created <= $2" + fmt.Sprintf(notIgnored, 4) + " ORDER BY created DESC LIMIT $3"},
+
+		statements.Statement{Dest: &s.countParents, Query: "SELECT count(*) FROM posts WHERE id = ANY($1) AND thread = $2"},
+		statements.Statement{Dest: &s.createPosts, Query: `INSERT INTO posts (author, created, forum, msg, parent, thread)
+		SELECT p.author, $4::timestamptz, $5::citext, p.msg, p.parent, $6::int
+		FROM unnest($1::citext[], $2::text[], $3::int[]) WITH ORDINALITY AS p(author, msg, parent, n)
+		ORDER BY p.n
+		RETURNING id`},
+
+		statements.Statement{Dest: &s.getThreadByID, Query: selectThread + " WHERE id = $1"},
+		statements.Statement{Dest: &s.getThreadBySlug, Query: selectThread + " WHERE slug = $1"},
+
+		statements.Statement{Dest: &s.getVote, Query: `SELECT vote FROM thread_vote
+		WHERE nickname = $1 AND thread_id = $2`},
+		statements.Statement{Dest: &s.createVote, Query: "INSERT INTO thread_vote (nickname, thread_id, vote) VALUES($1, $2, $3)"},
+		statements.Statement{Dest: &s.updateVote, Query: `UPDATE thread_vote SET vote = $1
+		WHERE nickname = $2 AND thread_id = $3`},
+
+		statements.Statement{Dest: &s.getPosts.Asc, Query: selectPost + " WHERE thread = $1 ORDER BY id ASC LIMIT $2"},
+		statements.Statement{Dest: &s.getPosts.Desc, Query: selectPost + " WHERE thread = $1 ORDER BY id DESC LIMIT $2"},
+		statements.Statement{Dest: &s.getPostsSince.Asc, Query: selectPost + " WHERE thread = $1 AND id > $2 ORDER BY id ASC LIMIT $3"},
+		statements.Statement{Dest: &s.getPostsSince.Desc, Query: selectPost + " WHERE thread = $1 AND id < $2 ORDER BY id DESC LIMIT $3"},
+
+		statements.Statement{Dest: &s.viewPosts.Asc, Query: selectPost + " WHERE thread = $1" + fmt.Sprintf(notIgnored, 3) + " ORDER BY id ASC LIMIT $2"},
+		statements.Statement{Dest: &s.viewPosts.Desc, Query: selectPost + " WHERE thread = $1" + fmt.Sprintf(notIgnored, 3) + " ORDER BY id DESC LIMIT $2"},
+		statements.Statement{Dest: &s.viewPostsSince.Asc, Query: selectPost + " WHERE thread = $1 AND id > $2" + fmt.Sprintf(notIgnored, 4) + " ORDER BY id ASC LIMIT $3"},
+		statements.Statement{Dest: &s.viewPostsSince.Desc, Query: selectPost + " WHERE thread = $1 AND id < $2" + fmt.Sprintf(notIgnored, 4) + " ORDER BY id DESC LIMIT $3"},
+
+		statements.Statement{Dest: &s.getPostsTree.Asc, Query: selectPost + " WHERE thread = $1 ORDER BY path ASC, id ASC LIMIT $2"},
+		statements.Statement{Dest: &s.getPostsTree.Desc, Query: selectPost + " WHERE thread = $1 ORDER BY path DESC, id DESC LIMIT $2"},
+		statements.Statement{Dest: &s.getPostsTreeSince.Asc, Query: selectPost + `
+		WHERE thread = $1 AND path > (SELECT path FROM posts WHERE id = $2)
+		ORDER BY path ASC, id ASC LIMIT $3`},
+		statements.Statement{Dest: &s.getPostsTreeSince.Desc, Query: selectPost + `
+		WHERE thread = $1 AND path < (SELECT path FROM posts WHERE id = $2)
+		ORDER BY path DESC, id DESC LIMIT $3`},
+
+		statements.Statement{Dest: &s.getPostsParent.Asc, Query: selectPost + `
+		WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 ORDER BY id LIMIT $2)
+		ORDER BY path, id`},
+		statements.Statement{Dest: &s.getPostsParent.Desc, Query: selectPost + `
+		WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 ORDER BY id DESC LIMIT $2)
+		ORDER BY path[1] DESC, path, id`},
+		statements.Statement{Dest: &s.getPostsParentSince.Asc, Query: selectPost + `
+		WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 AND path[1] >
+		(SELECT path[1] FROM posts WHERE id = $2) ORDER BY id ASC LIMIT $3) ORDER BY path, id`},
+		statements.Statement{Dest: &s.getPostsParentSince.Desc, Query: selectPost + `
+		WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 AND path[1] <
+		(SELECT path[1] FROM posts WHERE id = $2) ORDER BY id DESC LIMIT $3) ORDER BY path[1] DESC, path, id`},
+
+		statements.Statement{Dest: &s.updateThread, Query: `UPDATE threads SET title = $1, msg = $2
+		WHERE slug = $3 OR id = $4
+		RETURNING author, created, forum, id, msg, slug, title`},
+
+		statements.Statement{Dest: &s.getUsers.Asc, Query: selectUser + " WHERE fu.forum_slug = $1 ORDER BY u.nickname ASC LIMIT $2"},
+		statements.Statement{Dest: &s.getUsers.Desc, Query: selectUser + " WHERE fu.forum_slug = $1 ORDER BY u.nickname DESC LIMIT $2"},
+		statements.Statement{Dest: &s.getUsersSince.Asc, Query: selectUser + " WHERE fu.forum_slug = $1 AND fu.nickname > $2 ORDER BY u.nickname ASC LIMIT $3"},
+		statements.Statement{Dest: &s.getUsersSince.Desc, Query: selectUser + " WHERE fu.forum_slug = $1 AND fu.nickname < $2 ORDER BY u.nickname DESC LIMIT $3"},
+
+		statements.Statement{Dest: &s.ignoredAuthors, Query: `SELECT i.nickname FROM user_ignore AS ig
+		JOIN users AS i ON i.id = ig.ignored_id
+		JOIN users AS v ON v.id = ig.user_id
+		WHERE v.nickname = $1`},
+
+		statements.Statement{Dest: &s.userThreads.Asc, Query: selectThread + byAuthor + " ORDER BY created ASC, id ASC LIMIT $4"},
+		statements.Statement{Dest: &s.userThreads.Desc, Query: selectThread + byAuthor + " ORDER BY created DESC, id DESC LIMIT $4"},
+		statements.Statement{Dest: &s.userThreadsSince.Asc, Query: selectThread + byAuthor + " AND created >= $4 ORDER BY created ASC, id ASC LIMIT $5"},
+		statements.Statement{Dest: &s.userThreadsSince.Desc, Query: selectThread + byAuthor + " AND created <= $4 ORDER BY created DESC, id DESC LIMIT $5"},
+		statements.Statement{Dest: &s.userPosts.Asc, Query: selectPost + byAuthor + " ORDER BY id ASC LIMIT $4"},
+		statements.Statement{Dest: &s.userPosts.Desc, Query: selectPost + byAuthor + " ORDER BY id DESC LIMIT $4"},
+		statements.Statement{Dest: &s.userPostsSince.Asc, Query: selectPost + byAuthor + " AND id > $4 ORDER BY id ASC LIMIT $5"},
+		statements.Statement{Dest: &s.userPostsSince.Desc, Query: selectPost + byAuthor + " AND id < $4 ORDER BY id DESC LIMIT $5"},
+		statements.Statement{Dest: &s.userVotes.Asc, Query: selectVote + byVoter + " ORDER BY v.thread_id ASC LIMIT $4"},
+		statements.Statement{Dest: &s.userVotes.Desc, Query: selectVote + byVoter + " ORDER BY v.thread_id DESC LIMIT $4"},
+		statements.Statement{Dest: &s.userVotesSince.Asc, Query: selectVote + byVoter + " AND v.thread_id > $4 ORDER BY v.thread_id ASC LIMIT $5"},
+		statements.Statement{Dest: &s.userVotesSince.Desc, Query: selectVote + byVoter + " AND v.thread_id < $4 ORDER BY v.thread_id DESC LIMIT $5"},
+
+		statements.Statement{Dest: &s.privateForums, Query: "SELECT slug FROM forums WHERE visibility = 'private'"},
+		statements.Statement{Dest: &s.getSettings, Query: `SELECT max_post_length, post_interval, allow_votes, owner_threads, nested_replies, max_reply_depth
+		FROM forum_settings WHERE forum_slug = $1`},
+		statements.Statement{Dest: &s.setSettings, Query: `INSERT INTO forum_settings
+		(forum_slug, max_post_length, post_interval, allow_votes, owner_threads, nested_replies, max_reply_depth)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (forum_slug) DO UPDATE SET
+		max_post_length = excluded.max_post_length, post_interval = excluded.post_interval,
+		allow_votes = excluded.allow_votes, owner_threads = excluded.owner_threads,
+		nested_replies = excluded.nested_replies, max_reply_depth = excluded.max_reply_depth, updated = now()`},
+		// Locks authors in a fixed order, so batches sharing some of them can't deadlock.
+		statements.Statement{Dest: &s.lockAuthors, Query: `SELECT pg_advisory_xact_lock(hashtext(lower($1)), hashtext(a.author))
+		FROM (SELECT DISTINCT lower(author) AS author FROM unnest($2::text[]) AS u(author) ORDER BY 1) AS a`},
+		// Walks each author's posts from the latest, which index_posts_author_id keeps cheap.
+		statements.Statement{Dest: &s.lastPosted, Query: `SELECT a.author, p.created FROM unnest($2::citext[]) AS a(author)
+		JOIN LATERAL (
+			SELECT created FROM posts WHERE author = a.author AND forum = $1 ORDER BY id DESC LIMIT 1
+		) AS p ON true`},
+		statements.Statement{Dest: &s.replyDepth, Query: "SELECT COALESCE(max(array_length(path, 1)), 0) FROM posts WHERE id = ANY($1) AND thread = $2"},
+
+		statements.Statement{Dest: &s.getPost, Query: "SELECT author, created, forum, id, msg, thread, isEdited, parent FROM posts WHERE id = $1"},
+		statements.Statement{Dest: &s.updatePost, Query: `UPDATE posts SET msg = $1, isEdited = true WHERE id = $2
+		RETURNING author, created, forum, id, msg, thread, isEdited, parent`},
+
+		statements.Statement{Dest: &s.clear, Query: "TRUNCATE TABLE users, forums, forum_user, threads, thread_vote, posts, sessions, nickname_history, email_verifications, forum_moderators, user_ignore, forum_members, forum_settings"},
+		statements.Statement{Dest: &s.serviceInfo, Query: `SELECT
+		(SELECT count(*) FROM forums), (SELECT count(*) FROM threads),
+		(SELECT count(*) FROM posts), (SELECT count(*) FROM users)`},
+
+		statements.Statement{Dest: &s.checkThreadByID, Query: "SELECT id FROM threads WHERE id = $1"},
+		statements.Statement{Dest: &s.checkThreadBySlug, Query: "SELECT id FROM threads WHERE slug = $1"},
+		statements.Statement{Dest: &s.getThreadForum, Query: "SELECT forum FROM threads WHERE id = $1"},
+		statements.Statement{Dest: &s.getThreadIDAndForum, Query: "SELECT forum, id FROM threads WHERE slug = $1"},
+	)
+	if err != nil {
+		return nil, err
 	}
+
+	return ForumRepository{
+		db:    db,
+		stmts: s,
+	}, nil
 }
 
-func (f ForumRepository) Create(model models.Forum) error {
-	_, err := f.db.Exec(
-		"INSERT INTO forums (slug, title, user_nickname) VALUES($1, $2, $3)",
-		model.Slug, model.Title, model.User,
+func (f ForumRepository) Create(ctx context.Context, model models.Forum) error {
+	defer metrics.ObserveQuery("forum", "Create", time.Now())
+
+	_, err := f.stmts.create.ExecContext(
+		ctx,
+		model.Slug, model.Title, model.User, model.Parent, model.Visibility,
 	)
 
 	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return fmt.Errorf("forum with slug '%v' already exists: %w", model.Slug, forum.ErrDataConflict)
+		}
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find user with nickname '%v': %w", model.User, user.ErrUserDoesntExists)
+		}
 		return fmt.Errorf("couldn't create new forum. Error: %w", err)
 	}
 
 	return nil
 }
 
-func (f ForumRepository) Get(slug string) (models.Forum, error) {
+func (f ForumRepository) Get(ctx context.Context, slug string) (models.Forum, error) {
+	defer metrics.ObserveQuery("forum", "Get", time.Now())
+
 	var model models.Forum
-	err := f.db.QueryRow(
-		`SELECT slug, title, user_nickname, thread_count, post_count FROM forums
-		WHERE slug = $1`,
+	err := f.stmts.get.QueryRowContext(
+		ctx,
 		slug,
-	).Scan(&model.Slug, &model.Title, &model.User, &model.Threads, &model.Posts)
+	).Scan(&model.Slug, &model.Title, &model.User, &model.Parent, &model.Threads, &model.Posts, &model.Visibility, &model.Created, &model.Activity)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Forum{}, fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
 		return models.Forum{}, fmt.Errorf("couldn't get forum with slug '%v'. Error: %w", slug, err)
 	}
 
 	return model, nil
 }
 
-func (f ForumRepository) CreateThread(thread *models.Thread) error {
-	var err error
-	thread.Forum, err = f.CheckForum(thread.Forum)
+func (f ForumRepository) Update(ctx context.Context, model models.Forum) (models.Forum, error) {
+	defer metrics.ObserveQuery("forum", "Update", time.Now())
 
+	tx, err := f.db.BeginTx(ctx, nil)
 	if err != nil {
-		return forum.ErrForumDoesntExists
+		return models.Forum{}, err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	err = f.db.QueryRow(
-		`INSERT INTO threads (author, created, forum, msg, title, slug)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
-		thread.Author, thread.Created, thread.Forum, thread.Message, thread.Title, thread.Slug,
-	).Scan(&thread.ID)
+	slug := model.Slug
+	if model.Parent != nil {
+		err = f.setParent(ctx, tx, slug, *model.Parent)
+		if err != nil {
+			return models.Forum{}, err
+		}
+	}
+
+	err = tx.StmtContext(ctx, f.stmts.update).QueryRowContext(
+		ctx,
+		slug, model.Title, model.User, model.Visibility,
+	).Scan(&model.Slug, &model.Title, &model.User, &model.Parent, &model.Threads, &model.Posts, &model.Visibility, &model.Created, &model.Activity)
 
 	if err != nil {
-		return fmt.Errorf("couldn't create thread. Error: %w", err)
+		if err == sql.ErrNoRows {
+			return models.Forum{}, fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		if apperror.IsForeignKeyViolation(err) {
+			return models.Forum{}, fmt.Errorf("can't find new owner '%v' of forum '%v': %w", model.User, slug, user.ErrUserDoesntExists)
+		}
+		return models.Forum{}, fmt.Errorf("couldn't update forum with slug '%v'. Error: %w", slug, err)
+	}
+
+	return model, tx.Commit()
+}
+
+func (f ForumRepository) Delete(ctx context.Context, slug string) (models.ForumDeletion, error) {
+	defer metrics.ObserveQuery("forum", "Delete", time.Now())
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ForumDeletion{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", hierarchyLockID)
+	if err != nil {
+		return models.ForumDeletion{}, fmt.Errorf("couldn't lock forum hierarchy. Error: %w", err)
+	}
+
+	err = tx.StmtContext(ctx, f.stmts.lockForum).QueryRowContext(ctx, slug).Scan(&slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ForumDeletion{}, fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return models.ForumDeletion{}, fmt.Errorf("couldn't lock forum with slug '%v'. Error: %w", slug, err)
+	}
+
+	var hasChildren bool
+	err = tx.StmtContext(ctx, f.stmts.hasChildren).QueryRowContext(ctx, slug).Scan(&hasChildren)
+	if err != nil {
+		return models.ForumDeletion{}, fmt.Errorf("couldn't check subforums of '%v'. Error: %w", slug, err)
+	}
+
+	if hasChildren {
+		return models.ForumDeletion{}, fmt.Errorf("can't delete forum '%v': %w", slug, forum.ErrForumHasChildren)
+	}
+
+	deletion := models.ForumDeletion{Slug: slug}
+	err = tx.StmtContext(ctx, f.stmts.countForumVotes).QueryRowContext(ctx, slug).Scan(&deletion.Votes)
+	if err != nil {
+		return models.ForumDeletion{}, fmt.Errorf("couldn't count votes in forum '%v'. Error: %w", slug, err)
+	}
+
+	for _, step := range []struct {
+		stmt    *sql.Stmt
+		deleted *int64
+	}{
+		{f.stmts.deleteForumPosts, &deletion.Posts},
+		{f.stmts.deleteForumThreads, &deletion.Threads},
+		{f.stmts.deleteForumUsers, &deletion.Users},
+		{f.stmts.deleteForum, nil},
+	} {
+		result, err := tx.StmtContext(ctx, step.stmt).ExecContext(ctx, slug)
+		if err != nil {
+			return models.ForumDeletion{}, fmt.Errorf("couldn't delete forum '%v'. Error: %w", slug, err)
+		}
+
+		if step.deleted != nil {
+			*step.deleted, err = result.RowsAffected()
+			if err != nil {
+				return models.ForumDeletion{}, fmt.Errorf("couldn't delete forum '%v'. Error: %w", slug, err)
+			}
+		}
+	}
+
+	return deletion, tx.Commit()
+}
+
+// setParent moves the forum within tx, holding the hierarchy lock until tx ends.
+func (f ForumRepository) setParent(ctx context.Context, tx *sql.Tx, slug string, parent string) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", hierarchyLockID)
+	if err != nil {
+		return fmt.Errorf("couldn't lock forum hierarchy. Error: %w", err)
+	}
+
+	if parent != "" {
+		var cycle bool
+		err = tx.StmtContext(ctx, f.stmts.isAncestor).QueryRowContext(ctx, slug, parent).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("couldn't check ancestors of forum '%v'. Error: %w", parent, err)
+		}
+
+		if cycle {
+			return fmt.Errorf("can't move forum '%v' below '%v': %w", slug, parent, forum.ErrForumCycle)
+		}
+	}
+
+	result, err := tx.StmtContext(ctx, f.stmts.setParent).ExecContext(ctx, slug, parent)
+	if err != nil {
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find forum with slug '%v': %w", parent, forum.ErrForumDoesntExists)
+		}
+		return fmt.Errorf("couldn't move forum '%v'. Error: %w", slug, err)
+	}
+
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't move forum '%v'. Error: %w", slug, err)
+	}
+
+	if moved == 0 {
+		return fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
 	}
 
 	return nil
 }
 
-func (f ForumRepository) CheckForum(slug string) (string, error) {
-	err := f.db.QueryRow(
-		"SELECT slug FROM forums WHERE slug = $1",
-		slug,
-	).Scan(&slug)
+func (f ForumRepository) GetBreadcrumb(ctx context.Context, slug string) ([]models.ForumLink, error) {
+	defer metrics.ObserveQuery("forum", "GetBreadcrumb", time.Now())
 
+	rows, err := f.stmts.breadcrumb.QueryContext(ctx, slug)
 	if err != nil {
-		return "", fmt.Errorf("couldn't get forum with slug '%v'. Error: %w", slug, err)
+		return nil, fmt.Errorf("couldn't get ancestors of forum '%v'. Error: %w", slug, err)
 	}
+	defer rows.Close()
 
-	return slug, nil
+	links := make([]models.ForumLink, 0)
+	for rows.Next() {
+		var link models.ForumLink
+		err = rows.Scan(&link.Slug, &link.Title)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan ancestor of forum '%v'. Error: %w", slug, err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
 }
 
-func (f ForumRepository) GetThreads(slug string, limit string, since string, desc string) ([]models.Thread, error) {
-	query := "SELECT author, created, forum, id, msg, slug, title, votes FROM threads WHERE forum = $1"
+func (f ForumRepository) GetSubtreeCounters(ctx context.Context, slug string, hidden []string) (int, int, error) {
+	defer metrics.ObserveQuery("forum", "GetSubtreeCounters", time.Now())
+
+	var threads, posts int
+	err := f.stmts.subtreeCounters.QueryRowContext(ctx, slug, hiddenForums(hidden)).Scan(&threads, &posts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return 0, 0, fmt.Errorf("couldn't count threads and posts below forum '%v'. Error: %w", slug, err)
+	}
+
+	return threads, posts, nil
+}
 
-	args := make([]interface{}, 0, 4)
-	args = append(args, slug)
+func (f ForumRepository) GetForums(ctx context.Context, params forum.ListParams) ([]models.Forum, error) {
+	defer metrics.ObserveQuery("forum", "GetForums", time.Now())
+
+	var ordered statements.Ordered
+	switch params.Sort {
+	case forum.SortCreated:
+		ordered = f.stmts.forumsByCreated
+	case forum.SortPosts:
+		ordered = f.stmts.forumsByPosts
+	case forum.SortThreads:
+		ordered = f.stmts.forumsByThreads
+	case forum.SortActivity:
+		ordered = f.stmts.forumsByActivity
+	default:
+		ordered = f.stmts.forumsByTitle
+	}
 
-	var operator string
-	if desc == "" || desc == "false" {
-		operator = ">"
-	} else {
-		operator = "<"
+	var pattern interface{}
+	if params.Query != "" {
+		pattern = "%" + statements.EscapeLike(params.Query) + "%"
 	}
 
-	if since != "" {
-		query += fmt.Sprintf(" AND created %v= $2", operator)
-		args = append(args, since)
+	rows, err := ordered.Pick(params.Desc).QueryContext(
+		ctx,
+		pattern, statements.Nullable(params.Since), statements.Limit(params.Limit),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get forums. Error: %w", err)
 	}
+	defer rows.Close()
 
-	if desc == "" || desc == "false" {
-		desc = "ASC"
+	forums := make([]models.Forum, 0)
+	for rows.Next() {
+		var model models.Forum
+		err = rows.Scan(&model.Slug, &model.Title, &model.User, &model.Parent, &model.Threads, &model.Posts, &model.Visibility, &model.Created, &model.Activity)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan forum. Error: %w", err)
+		}
+		forums = append(forums, model)
+	}
+
+	return forums, rows.Err()
+}
+
+func (f ForumRepository) GetChildren(ctx context.Context, slug string, subtree bool, hidden []string) ([]models.Forum, error) {
+	defer metrics.ObserveQuery("forum", "GetChildren", time.Now())
+
+	var rows *sql.Rows
+	var err error
+	if subtree {
+		rows, err = f.stmts.childrenSubtree.QueryContext(ctx, slug, hiddenForums(hidden))
 	} else {
-		desc = "DESC"
+		rows, err = f.stmts.children.QueryContext(ctx, slug)
 	}
-	query += fmt.Sprintf(" ORDER BY created %v", desc)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get subforums of '%v'. Error: %w", slug, err)
+	}
+	defer rows.Close()
+
+	forums := make([]models.Forum, 0)
+	for rows.Next() {
+		var model models.Forum
+		err = rows.Scan(&model.Slug, &model.Title, &model.User, &model.Parent, &model.Threads, &model.Posts, &model.Visibility, &model.Created, &model.Activity)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan subforum of '%v'. Error: %w", slug, err)
+		}
+		forums = append(forums, model)
+	}
+
+	return forums, rows.Err()
+}
+
+func (f ForumRepository) CreateThread(ctx context.Context, thread *models.Thread) error {
+	defer metrics.ObserveQuery("forum", "CreateThread", time.Now())
+
+	var err error
+	thread.Forum, err = f.CheckForum(ctx, thread.Forum)
+	if err != nil {
+		return err
+	}
+
+	err = f.stmts.createThread.QueryRowContext(
+		ctx,
+		thread.Author, thread.Created, thread.Forum, thread.Message, thread.Title, thread.Slug,
+	).Scan(&thread.ID)
+
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return fmt.Errorf("thread with slug '%v' already exists: %w", *thread.Slug, forum.ErrDataConflict)
+		}
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find user with nickname '%v': %w", thread.Author, user.ErrUserDoesntExists)
+		}
+		return fmt.Errorf("couldn't create thread. Error: %w", err)
+	}
+
+	return nil
+}
+
+func (f ForumRepository) CheckForum(ctx context.Context, slug string) (string, error) {
+	defer metrics.ObserveQuery("forum", "CheckForum", time.Now())
+
+	err := f.stmts.checkForum.QueryRowContext(
+		ctx,
+		slug,
+	).Scan(&slug)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't get forum with slug '%v'. Error: %w", slug, err)
+	}
+
+	return slug, nil
+}
+
+func (f ForumRepository) GetThreads(ctx context.Context, slug string, limit string, since string, desc string, viewer string) ([]models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "GetThreads", time.Now())
 
 	limitInt, err := strconv.Atoi(limit)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("limit '%v' is not a number: %w", limit, apperror.ErrInvalidParam)
 	}
-	query += fmt.Sprintf(" LIMIT %v", limitInt)
 
-	rows, err := f.db.Query(query, args...)
+	isDesc := desc != "" && desc != "false"
+
+	var rows *sql.Rows
+	switch {
+	case viewer != "" && since != "":
+		rows, err = f.stmts.viewThreadsSince.Pick(isDesc).QueryContext(ctx, slug, since, limitInt, viewer)
+	case viewer != "":
+		rows, err = f.stmts.viewThreads.Pick(isDesc).QueryContext(ctx, slug, limitInt, viewer)
+	case since != "":
+		rows, err = f.stmts.getThreadsSince.Pick(isDesc).QueryContext(ctx, slug, since, limitInt)
+	default:
+		rows, err = f.stmts.getThreads.Pick(isDesc).QueryContext(ctx, slug, limitInt)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -134,102 +707,191 @@ func (f ForumRepository) GetThreads(slug string, limit string, since string, des
 	return threads, nil
 }
 
-func (f ForumRepository) CreatePosts(thread models.Thread, posts []models.Post) error {
-	if posts[0].Parent != 0 {
-		var parentThread int
-		err := f.db.QueryRow(
-			"SELECT thread FROM posts WHERE id = $1",
-			posts[0].Parent,
-		).Scan(&parentThread)
+func (f ForumRepository) CreatePosts(ctx context.Context, thread models.Thread, posts []models.Post, interval time.Duration) error {
+	defer metrics.ObserveQuery("forum", "CreatePosts", time.Now())
+
+	parents := make(map[int]bool)
+	for _, post := range posts {
+		if post.Parent != 0 {
+			parents[post.Parent] = true
+		}
+	}
+
+	if len(parents) != 0 {
+		ids := make([]int64, 0, len(parents))
+		for id := range parents {
+			ids = append(ids, int64(id))
+		}
+
+		var found int
+		err := f.stmts.countParents.QueryRowContext(
+			ctx,
+			pq.Array(ids), thread.ID,
+		).Scan(&found)
 
 		if err != nil {
 			return fmt.Errorf("couldn't get thread id from posts: %w", err)
 		}
 
-		if parentThread != thread.ID {
-			return forum.ErrWrongParent
+		if found != len(ids) {
+			return fmt.Errorf("posts in thread %v: %w", thread.ID, forum.ErrWrongParent)
 		}
 	}
 
-	query := `INSERT INTO posts(author, created, forum, msg, parent, thread) VALUES `
-	var args []interface{}
+	authors := make([]string, 0, len(posts))
+	messages := make([]string, 0, len(posts))
+	parentIDs := make([]int64, 0, len(posts))
+
+	for _, post := range posts {
+		authors = append(authors, post.Author)
+		messages = append(messages, post.Message)
+		parentIDs = append(parentIDs, int64(post.Parent))
+	}
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't begin transaction. Error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if interval != 0 {
+		err = f.checkInterval(ctx, tx, thread.Forum, interval, authors)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Taken after the locks, so a batch that had to wait isn't dated before the one it waited for.
 	created := strfmt.DateTime(time.Now())
+	rows, err := tx.StmtContext(ctx, f.stmts.createPosts).QueryContext(
+		ctx,
+		pq.Array(authors), pq.Array(messages), pq.Array(parentIDs), time.Time(created), thread.Forum, thread.ID,
+	)
+	if err != nil {
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find post author: %w", user.ErrUserDoesntExists)
+		}
+		return fmt.Errorf("couldn't insert posts: %w", err)
+	}
+	defer rows.Close()
 
-	for i, post := range posts {
+	ids := make([]int, 0, len(posts))
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return fmt.Errorf("couldn't scan post id: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find post author: %w", user.ErrUserDoesntExists)
+		}
+		return fmt.Errorf("couldn't insert posts: %w", err)
+	}
+	rows.Close()
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("couldn't insert posts: %w", err)
+	}
+
+	for i := range posts {
+		posts[i].ID = ids[i]
 		posts[i].Forum = thread.Forum
 		posts[i].Thread = thread.ID
 		posts[i].Created = created
+	}
 
-		query += fmt.Sprintf(
-			"($%d, $%d, $%d, $%d, $%d, $%d),",
-			i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6,
-		)
+	return nil
+}
 
-		args = append(args, post.Author, created, thread.Forum, post.Message, post.Parent, thread.ID)
+// checkInterval makes sure none of the authors has posted in the forum within interval.
+// It locks each author in the forum until tx ends, so concurrent batches take turns.
+func (f ForumRepository) checkInterval(ctx context.Context, tx *sql.Tx, slug string, interval time.Duration, authors []string) error {
+	_, err := tx.StmtContext(ctx, f.stmts.lockAuthors).ExecContext(ctx, slug, pq.Array(authors))
+	if err != nil {
+		return fmt.Errorf("couldn't lock authors in forum '%v'. Error: %w", slug, err)
 	}
 
-	query = query[:len(query)-1]
-	query += ` RETURNING id`
-
-	rows, err := f.db.Query(query, args...)
+	rows, err := tx.StmtContext(ctx, f.stmts.lastPosted).QueryContext(ctx, slug, pq.Array(authors))
 	if err != nil {
-		return fmt.Errorf("couldn't insert posts: %w", err)
+		return fmt.Errorf("couldn't get latest posts in forum '%v'. Error: %w", slug, err)
 	}
 	defer rows.Close()
 
-	var idx int
+	now := time.Now()
+	var (
+		author  string
+		created time.Time
+	)
 	for rows.Next() {
-		err := rows.Scan(&posts[idx].ID)
+		err = rows.Scan(&author, &created)
 		if err != nil {
-			return fmt.Errorf("couldn't scan post id: %w", err)
+			return err
 		}
 
-		idx++
+		if wait := created.Add(interval).Sub(now); wait > 0 {
+			seconds := int(math.Ceil(wait.Seconds()))
+			return fmt.Errorf("'%v' may post again in %v seconds: %w", author, seconds, forum.ErrPostTooSoon)
+		}
 	}
 
-	return nil
+	return rows.Err()
 }
 
-func (f ForumRepository) GetThreadByID(id int) (models.Thread, error) {
+func (f ForumRepository) GetThreadByID(ctx context.Context, id int) (models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "GetThreadByID", time.Now())
+
 	var thread models.Thread
-	err := f.db.QueryRow(
-		`SELECT author, created, forum, id, msg, slug, title, votes FROM threads
-		WHERE id = $1`,
+	err := f.stmts.getThreadByID.QueryRowContext(
+		ctx,
 		id,
 	).Scan(&thread.Author, &thread.Created, &thread.Forum, &thread.ID, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
 
 	if err != nil {
-		return models.Thread{}, err
+		if err == sql.ErrNoRows {
+			return models.Thread{}, fmt.Errorf("can't find thread with id %v: %w", id, forum.ErrThreadDoesntExists)
+		}
+		return models.Thread{}, fmt.Errorf("couldn't get thread with id %v. Error: %w", id, err)
 	}
 
 	return thread, nil
 }
 
-func (f ForumRepository) GetThreadBySlug(slug string) (models.Thread, error) {
+func (f ForumRepository) GetThreadBySlug(ctx context.Context, slug string) (models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "GetThreadBySlug", time.Now())
+
 	var thread models.Thread
-	err := f.db.QueryRow(
-		`SELECT author, created, forum, id, msg, slug, title, votes FROM threads
-		WHERE slug = $1`,
+	err := f.stmts.getThreadBySlug.QueryRowContext(
+		ctx,
 		slug,
 	).Scan(&thread.Author, &thread.Created, &thread.Forum, &thread.ID, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
 
 	if err != nil {
-		return models.Thread{}, err
+		if err == sql.ErrNoRows {
+			return models.Thread{}, fmt.Errorf("can't find thread with slug '%v': %w", slug, forum.ErrThreadDoesntExists)
+		}
+		return models.Thread{}, fmt.Errorf("couldn't get thread with slug '%v'. Error: %w", slug, err)
 	}
 
 	return thread, nil
 }
 
-func (f ForumRepository) Vote(vote models.Vote) (models.Thread, error) {
+func (f ForumRepository) Vote(ctx context.Context, vote models.Vote) (models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "Vote", time.Now())
+
 	var (
 		thread models.Thread
 		err    error
 	)
 
 	if vote.ID != 0 {
-		thread, err = f.GetThreadByID(vote.ID)
+		thread, err = f.GetThreadByID(ctx, vote.ID)
 	} else {
-		thread, err = f.GetThreadBySlug(vote.Slug)
+		thread, err = f.GetThreadBySlug(ctx, vote.Slug)
 	}
 
 	if err != nil {
@@ -237,9 +899,8 @@ func (f ForumRepository) Vote(vote models.Vote) (models.Thread, error) {
 	}
 
 	var voteValue int
-	err = f.db.QueryRow(
-		`SELECT vote FROM thread_vote
-		WHERE nickname = $1 AND thread_id = $2`,
+	err = f.stmts.getVote.QueryRowContext(
+		ctx,
 		vote.Nickname, thread.ID,
 	).Scan(&voteValue)
 
@@ -248,12 +909,15 @@ func (f ForumRepository) Vote(vote models.Vote) (models.Thread, error) {
 	}
 
 	if err == sql.ErrNoRows {
-		_, err = f.db.Exec(
-			"INSERT INTO thread_vote (nickname, thread_id, vote) VALUES($1, $2, $3)",
+		_, err = f.stmts.createVote.ExecContext(
+			ctx,
 			vote.Nickname, thread.ID, vote.Voice,
 		)
 
 		if err != nil {
+			if apperror.IsForeignKeyViolation(err) {
+				return models.Thread{}, fmt.Errorf("can't find user with nickname '%v': %w", vote.Nickname, user.ErrUserDoesntExists)
+			}
 			return models.Thread{}, err
 		}
 
@@ -267,9 +931,8 @@ func (f ForumRepository) Vote(vote models.Vote) (models.Thread, error) {
 
 	thread.Votes = thread.Votes - voteValue + vote.Voice
 
-	_, err = f.db.Exec(
-		`UPDATE thread_vote SET vote = $1
-		WHERE nickname = $2 AND thread_id = $3`,
+	_, err = f.stmts.updateVote.ExecContext(
+		ctx,
 		vote.Voice, vote.Nickname, thread.ID,
 	)
 
@@ -280,43 +943,27 @@ func (f ForumRepository) Vote(vote models.Vote) (models.Thread, error) {
 	return thread, nil
 }
 
-func (f ForumRepository) GetPosts(slugOrID string, limit int, order string, since string) ([]models.Post, error) {
-	var sinceCond string
-	if since != "" {
-		if order == "DESC" {
-			sinceCond = fmt.Sprintf("AND id < %v", since)
-		} else {
-			sinceCond = fmt.Sprintf("AND id > %v", since)
-		}
-	}
+func (f ForumRepository) GetPosts(ctx context.Context, slugOrID string, limit int, order string, since string, viewer string) ([]models.Post, error) {
+	defer metrics.ObserveQuery("forum", "GetPosts", time.Now())
 
 	threadID, err := strconv.Atoi(slugOrID)
 	if err != nil {
-		threadID, err = f.CheckThreadBySlug(slugOrID)
+		threadID, err = f.CheckThreadBySlug(ctx, slugOrID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	var (
-		rows *sql.Rows
-	)
-
-	if limit != 0 {
-		rows, err = f.db.Query(
-			fmt.Sprintf(`SELECT author, created, forum, id, msg, parent, thread FROM posts
-			WHERE thread = $1 %v
-			ORDER BY id %v
-			LIMIT %v`, sinceCond, order, limit),
-			threadID,
-		)
-	} else {
-		rows, err = f.db.Query(
-			fmt.Sprintf(`SELECT author, created, forum, id, msg, parent, thread FROM posts
-			WHERE thread = $1 %v
-			ORDER BY id %v`, sinceCond, order),
-			threadID,
-		)
+	var rows *sql.Rows
+	switch {
+	case viewer != "" && since != "":
+		rows, err = f.stmts.viewPostsSince.Pick(order == "DESC").QueryContext(ctx, threadID, since, statements.Limit(limit), viewer)
+	case viewer != "":
+		rows, err = f.stmts.viewPosts.Pick(order == "DESC").QueryContext(ctx, threadID, statements.Limit(limit), viewer)
+	case since != "":
+		rows, err = f.stmts.getPostsSince.Pick(order == "DESC").QueryContext(ctx, threadID, since, statements.Limit(limit))
+	default:
+		rows, err = f.stmts.getPosts.Pick(order == "DESC").QueryContext(ctx, threadID, statements.Limit(limit))
 	}
 
 	if err != nil {
@@ -338,56 +985,22 @@ func (f ForumRepository) GetPosts(slugOrID string, limit int, order string, sinc
 	return posts, nil
 }
 
-func (f ForumRepository) GetPostsTree(slugOrID string, limit int, order string, since string) ([]models.Post, error) {
-	var desc bool
-	if order == "DESC" {
-		desc = true
-	} else {
-		desc = false
-	}
+func (f ForumRepository) GetPostsTree(ctx context.Context, slugOrID string, limit int, order string, since string) ([]models.Post, error) {
+	defer metrics.ObserveQuery("forum", "GetPostsTree", time.Now())
 
 	threadID, err := strconv.Atoi(slugOrID)
 	if err != nil {
-		threadID, err = f.CheckThreadBySlug(slugOrID)
+		threadID, err = f.CheckThreadBySlug(ctx, slugOrID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	var (
-		rows *sql.Rows
-	)
-
-	if since == "" {
-		if desc {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE thread = $1 ORDER BY path DESC, id  DESC LIMIT $2;`,
-				threadID, limit,
-			)
-		} else {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE thread = $1 ORDER BY path ASC, id  ASC LIMIT $2;`,
-				threadID, limit,
-			)
-		}
+	var rows *sql.Rows
+	if since != "" {
+		rows, err = f.stmts.getPostsTreeSince.Pick(order == "DESC").QueryContext(ctx, threadID, since, limit)
 	} else {
-		if desc {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE thread = $1 AND path < (SELECT path FROM posts WHERE id = $2)
-				ORDER BY path DESC, id  DESC LIMIT $3;`,
-				threadID, since, limit,
-			)
-		} else {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE thread = $1 AND path > (SELECT path FROM posts WHERE id = $2)
-				ORDER BY path ASC, id  ASC LIMIT $3;`,
-				threadID, since, limit,
-			)
-		}
+		rows, err = f.stmts.getPostsTree.Pick(order == "DESC").QueryContext(ctx, threadID, limit)
 	}
 	if err != nil {
 		return nil, err
@@ -412,58 +1025,22 @@ func (f ForumRepository) GetPostsTree(slugOrID string, limit int, order string,
 	return posts, nil
 }
 
-func (f ForumRepository) GetPostsParentTree(slugOrID string, limit int, order string, since string) ([]models.Post, error) {
-	var desc bool
-	if order == "DESC" {
-		desc = true
-	} else {
-		desc = false
-	}
+func (f ForumRepository) GetPostsParentTree(ctx context.Context, slugOrID string, limit int, order string, since string) ([]models.Post, error) {
+	defer metrics.ObserveQuery("forum", "GetPostsParentTree", time.Now())
 
 	threadID, err := strconv.Atoi(slugOrID)
 	if err != nil {
-		threadID, err = f.CheckThreadBySlug(slugOrID)
+		threadID, err = f.CheckThreadBySlug(ctx, slugOrID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	var (
-		rows *sql.Rows
-	)
-
-	if since == "" {
-		if desc {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 ORDER BY id DESC LIMIT $2)
-				ORDER BY path[1] DESC, path, id;`,
-				threadID, limit,
-			)
-		} else {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 ORDER BY id LIMIT $2)
-				ORDER BY path, id;`,
-				threadID, limit,
-			)
-		}
+	var rows *sql.Rows
+	if since != "" {
+		rows, err = f.stmts.getPostsParentSince.Pick(order == "DESC").QueryContext(ctx, threadID, since, limit)
 	} else {
-		if desc {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 AND path[1] <
-				(SELECT path[1] FROM posts WHERE id = $2) ORDER BY id DESC LIMIT $3) ORDER BY path[1] DESC, path, id;`,
-				threadID, since, limit,
-			)
-		} else {
-			rows, err = f.db.Query(
-				`SELECT author, created, forum, id, msg, parent, thread FROM posts
-				WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 AND path[1] >
-				(SELECT path[1] FROM posts WHERE id = $2) ORDER BY id ASC LIMIT $3) ORDER BY path, id;`,
-				threadID, since, limit,
-			)
-		}
+		rows, err = f.stmts.getPostsParent.Pick(order == "DESC").QueryContext(ctx, threadID, limit)
 	}
 
 	if err != nil {
@@ -489,7 +1066,9 @@ func (f ForumRepository) GetPostsParentTree(slugOrID string, limit int, order st
 	return posts, nil
 }
 
-func (f ForumRepository) UpdateThread(thread models.Thread) (models.Thread, error) {
+func (f ForumRepository) UpdateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "UpdateThread", time.Now())
+
 	if thread.Title == "" || thread.Message == "" {
 		var (
 			oldThread models.Thread
@@ -497,12 +1076,12 @@ func (f ForumRepository) UpdateThread(thread models.Thread) (models.Thread, erro
 		)
 
 		if thread.ID != 0 {
-			oldThread, err = f.GetThreadByID(thread.ID)
+			oldThread, err = f.GetThreadByID(ctx, thread.ID)
 		} else {
-			oldThread, err = f.GetThreadBySlug(*thread.Slug)
+			oldThread, err = f.GetThreadBySlug(ctx, *thread.Slug)
 		}
 		if err != nil {
-			return models.Thread{}, nil
+			return models.Thread{}, err
 		}
 
 		if thread.Title == "" {
@@ -514,71 +1093,33 @@ func (f ForumRepository) UpdateThread(thread models.Thread) (models.Thread, erro
 		}
 	}
 
-	err := f.db.QueryRow(
-		`UPDATE threads SET title = $1, msg = $2
-		WHERE slug = $3 OR id = $4
-		RETURNING author, created, forum, id, msg, slug, title`,
+	err := f.stmts.updateThread.QueryRowContext(
+		ctx,
 		thread.Title, thread.Message, thread.Slug, thread.ID,
 	).Scan(&thread.Author, &thread.Created, &thread.Forum, &thread.ID, &thread.Message, &thread.Slug, &thread.Title)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Thread{}, fmt.Errorf("can't find thread '%v': %w", *thread.Slug, forum.ErrThreadDoesntExists)
+		}
 		return models.Thread{}, err
 	}
 
 	return thread, nil
 }
 
-func (f ForumRepository) GetUsersFromForum(slug string, limit int, since string, desc string) ([]models.User, error) {
+func (f ForumRepository) GetUsersFromForum(ctx context.Context, slug string, limit int, since string, desc string) ([]models.User, error) {
+	defer metrics.ObserveQuery("forum", "GetUsersFromForum", time.Now())
+
 	var (
 		rows *sql.Rows
 		err  error
 	)
 
-	var compare string
-	if desc == "DESC" {
-		compare = "<"
-	} else {
-		compare = ">"
-	}
-
 	if since != "" {
-		if limit != 0 {
-			rows, err = f.db.Query(
-				fmt.Sprintf(`SELECT u.about, u.email, u.fullname, u.nickname FROM users AS u
-				JOIN forum_user AS fu ON u.nickname = fu.nickname
-				WHERE fu.forum_slug = $1 AND fu.nickname %v $2
-				ORDER BY u.nickname %v
-				LIMIT %v`, compare, desc, limit),
-				slug, since,
-			)
-		} else {
-			rows, err = f.db.Query(
-				fmt.Sprintf(`SELECT u.about, u.email, u.fullname, u.nickname FROM users AS u
-				JOIN forum_user AS fu ON u.nickname = fu.nickname
-				WHERE fu.forum_slug = $1 AND fu.nickname %v $2
-				ORDER BY u.nickname %v`, compare, desc),
-				slug, since,
-			)
-		}
+		rows, err = f.stmts.getUsersSince.Pick(desc == "DESC").QueryContext(ctx, slug, since, statements.Limit(limit))
 	} else {
-		if limit != 0 {
-			rows, err = f.db.Query(
-				fmt.Sprintf(`SELECT u.about, u.email, u.fullname, u.nickname FROM users AS u
-				JOIN forum_user AS fu ON u.nickname = fu.nickname
-				WHERE fu.forum_slug = $1
-				ORDER BY u.nickname %v
-				LIMIT %v`, desc, limit),
-				slug,
-			)
-		} else {
-			rows, err = f.db.Query(
-				fmt.Sprintf(`SELECT u.about, u.email, u.fullname, u.nickname FROM users AS u
-				JOIN forum_user AS fu ON u.nickname = fu.nickname
-				WHERE fu.forum_slug = $1
-				ORDER BY u.nickname %v`, desc),
-				slug,
-			)
-		}
+		rows, err = f.stmts.getUsers.Pick(desc == "DESC").QueryContext(ctx, slug, statements.Limit(limit))
 	}
 
 	if err != nil {
@@ -589,7 +1130,7 @@ func (f ForumRepository) GetUsersFromForum(slug string, limit int, since string,
 	users := make([]models.User, 0, limit)
 	user := models.User{}
 	for rows.Next() {
-		err = rows.Scan(&user.About, &user.Email, &user.Fullname, &user.Nickname)
+		err = rows.Scan(&user.About, &user.Email, &user.Fullname, &user.Nickname, &user.Reputation, &user.Verified)
 		if err != nil {
 			return nil, err
 		}
@@ -600,22 +1141,29 @@ func (f ForumRepository) GetUsersFromForum(slug string, limit int, since string,
 	return users, nil
 }
 
-func (f ForumRepository) GetPostDetails(id string) (models.Post, error) {
+func (f ForumRepository) GetPostDetails(ctx context.Context, id string) (models.Post, error) {
+	defer metrics.ObserveQuery("forum", "GetPostDetails", time.Now())
+
 	var post models.Post
-	err := f.db.QueryRow(
-		"SELECT author, created, forum, id, msg, thread, isEdited, parent FROM posts WHERE id = $1",
+	err := f.stmts.getPost.QueryRowContext(
+		ctx,
 		id,
 	).Scan(&post.Author, &post.Created, &post.Forum, &post.ID, &post.Message, &post.Thread, &post.IsEdited, &post.Parent)
 
 	if err != nil {
-		return models.Post{}, err
+		if err == sql.ErrNoRows {
+			return models.Post{}, fmt.Errorf("can't find post with id %v: %w", id, forum.ErrPostDoesntExists)
+		}
+		return models.Post{}, fmt.Errorf("couldn't get post with id %v. Error: %w", id, err)
 	}
 
 	return post, nil
 }
 
-func (f ForumRepository) UpdatePost(post models.Post) (models.Post, error) {
-	postDB, err := f.GetPostDetails(strconv.Itoa(post.ID))
+func (f ForumRepository) UpdatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	defer metrics.ObserveQuery("forum", "UpdatePost", time.Now())
+
+	postDB, err := f.GetPostDetails(ctx, strconv.Itoa(post.ID))
 	if err != nil {
 		return models.Post{}, err
 	}
@@ -624,9 +1172,8 @@ func (f ForumRepository) UpdatePost(post models.Post) (models.Post, error) {
 		return postDB, nil
 	}
 
-	err = f.db.QueryRow(
-		`UPDATE posts SET msg = $1, isEdited = true WHERE id = $2
-		RETURNING author, created, forum, id, msg, thread, isEdited, parent`,
+	err = f.stmts.updatePost.QueryRowContext(
+		ctx,
 		post.Message, post.ID,
 	).Scan(&post.Author, &post.Created, &post.Forum, &post.ID, &post.Message, &post.Thread, &post.IsEdited, &post.Parent)
 
@@ -637,10 +1184,10 @@ func (f ForumRepository) UpdatePost(post models.Post) (models.Post, error) {
 	return post, nil
 }
 
-func (f ForumRepository) ClearService() error {
-	_, err := f.db.Exec(
-		"TRUNCATE TABLE users, forums, forum_user, threads, thread_vote, posts",
-	)
+func (f ForumRepository) ClearService(ctx context.Context) error {
+	defer metrics.ObserveQuery("forum", "ClearService", time.Now())
+
+	_, err := f.stmts.clear.ExecContext(ctx)
 
 	if err != nil {
 		return err
@@ -649,36 +1196,11 @@ func (f ForumRepository) ClearService() error {
 	return nil
 }
 
-func (f ForumRepository) GetServiceInfo() (models.ServiceInfo, error) {
-	var info models.ServiceInfo
-	err := f.db.QueryRow(
-		"SELECT count(*) FROM forums",
-	).Scan(&info.Forum)
-
-	if err != nil {
-		return models.ServiceInfo{}, err
-	}
-
-	err = f.db.QueryRow(
-		"SELECT count(*) FROM threads",
-	).Scan(&info.Thread)
-
-	if err != nil {
-		return models.ServiceInfo{}, err
-	}
-
-	err = f.db.QueryRow(
-		"SELECT count(*) FROM posts",
-	).Scan(&info.Post)
-
-	if err != nil {
-		return models.ServiceInfo{}, err
-	}
-
-	err = f.db.QueryRow(
-		"SELECT count(*) FROM users",
-	).Scan(&info.User)
+func (f ForumRepository) GetServiceInfo(ctx context.Context) (models.ServiceInfo, error) {
+	defer metrics.ObserveQuery("forum", "GetServiceInfo", time.Now())
 
+	var info models.ServiceInfo
+	err := f.stmts.serviceInfo.QueryRowContext(ctx).Scan(&info.Forum, &info.Thread, &info.Post, &info.User)
 	if err != nil {
 		return models.ServiceInfo{}, err
 	}
@@ -686,54 +1208,270 @@ func (f ForumRepository) GetServiceInfo() (models.ServiceInfo, error) {
 	return info, nil
 }
 
-func (f ForumRepository) CheckThreadByID(id int) (int, error) {
-	err := f.db.QueryRow(
-		"SELECT id FROM threads WHERE id = $1",
+func (f ForumRepository) CheckThreadByID(ctx context.Context, id int) (int, error) {
+	defer metrics.ObserveQuery("forum", "CheckThreadByID", time.Now())
+
+	err := f.stmts.checkThreadByID.QueryRowContext(
+		ctx,
 		id,
 	).Scan(&id)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("can't find thread with id %v: %w", id, forum.ErrThreadDoesntExists)
+		}
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (f ForumRepository) CheckThreadBySlug(slug string) (int, error) {
+func (f ForumRepository) CheckThreadBySlug(ctx context.Context, slug string) (int, error) {
+	defer metrics.ObserveQuery("forum", "CheckThreadBySlug", time.Now())
+
 	var id int
-	err := f.db.QueryRow(
-		"SELECT id FROM threads WHERE slug = $1",
+	err := f.stmts.checkThreadBySlug.QueryRowContext(
+		ctx,
 		slug,
 	).Scan(&id)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("can't find thread with slug '%v': %w", slug, forum.ErrThreadDoesntExists)
+		}
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (f ForumRepository) GetThreadIDAndForum(slugOrID string) (models.Thread, error) {
+func (f ForumRepository) GetThreadIDAndForum(ctx context.Context, slugOrID string) (models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "GetThreadIDAndForum", time.Now())
+
 	var (
 		thread models.Thread
 		err    error
 	)
 	thread.ID, err = strconv.Atoi(slugOrID)
 	if err != nil {
-		err = f.db.QueryRow(`
-			SELECT forum, id FROM threads WHERE slug = $1`,
+		err = f.stmts.getThreadIDAndForum.QueryRowContext(
+			ctx,
 			slugOrID,
 		).Scan(&thread.Forum, &thread.ID)
 	} else {
-		err = f.db.QueryRow(
-			"SELECT forum FROM threads WHERE id = $1",
+		err = f.stmts.getThreadForum.QueryRowContext(
+			ctx,
 			thread.ID,
 		).Scan(&thread.Forum)
 	}
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Thread{}, fmt.Errorf("can't find thread '%v': %w", slugOrID, forum.ErrThreadDoesntExists)
+		}
 		return models.Thread{}, err
 	}
 
 	return thread, nil
 }
+
+// activity runs the plain or the since variant of a per-user listing.
+func activity(ctx context.Context, plain, since statements.Ordered, params forum.ActivityParams) (*sql.Rows, error) {
+	var forumSlug interface{}
+	if params.Forum != "" {
+		forumSlug = params.Forum
+	}
+
+	if params.Since != "" {
+		return since.Pick(params.Desc).QueryContext(ctx, params.Nickname, forumSlug, hiddenForums(params.Hidden), params.Since, statements.Limit(params.Limit))
+	}
+
+	return plain.Pick(params.Desc).QueryContext(ctx, params.Nickname, forumSlug, hiddenForums(params.Hidden), statements.Limit(params.Limit))
+}
+
+// hiddenForums passes forums to leave out as an array parameter. A NULL one would leave every forum out.
+func hiddenForums(slugs []string) interface{} {
+	if slugs == nil {
+		slugs = []string{}
+	}
+
+	return pq.Array(slugs)
+}
+
+func (f ForumRepository) GetUserThreads(ctx context.Context, params forum.ActivityParams) ([]models.Thread, error) {
+	defer metrics.ObserveQuery("forum", "GetUserThreads", time.Now())
+
+	rows, err := activity(ctx, f.stmts.userThreads, f.stmts.userThreadsSince, params)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get threads of '%v'. Error: %w", params.Nickname, err)
+	}
+	defer rows.Close()
+
+	threads := make([]models.Thread, 0, params.Limit)
+	var thread models.Thread
+	for rows.Next() {
+		err = rows.Scan(&thread.Author, &thread.Created, &thread.Forum, &thread.ID, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
+		if err != nil {
+			return nil, err
+		}
+
+		threads = append(threads, thread)
+	}
+
+	return threads, rows.Err()
+}
+
+func (f ForumRepository) GetUserPosts(ctx context.Context, params forum.ActivityParams) ([]models.Post, error) {
+	defer metrics.ObserveQuery("forum", "GetUserPosts", time.Now())
+
+	rows, err := activity(ctx, f.stmts.userPosts, f.stmts.userPostsSince, params)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get posts of '%v'. Error: %w", params.Nickname, err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.Post, 0, params.Limit)
+	var post models.Post
+	for rows.Next() {
+		err = rows.Scan(&post.Author, &post.Created, &post.Forum, &post.ID, &post.Message, &post.Parent, &post.Thread)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (f ForumRepository) GetUserVotes(ctx context.Context, params forum.ActivityParams) ([]models.ThreadVote, error) {
+	defer metrics.ObserveQuery("forum", "GetUserVotes", time.Now())
+
+	rows, err := activity(ctx, f.stmts.userVotes, f.stmts.userVotesSince, params)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get votes of '%v'. Error: %w", params.Nickname, err)
+	}
+	defer rows.Close()
+
+	votes := make([]models.ThreadVote, 0, params.Limit)
+	var vote models.ThreadVote
+	for rows.Next() {
+		thread := &vote.Thread
+		err = rows.Scan(&vote.Voice, &thread.Author, &thread.Created, &thread.Forum, &thread.ID, &thread.Message, &thread.Slug, &thread.Title, &thread.Votes)
+		if err != nil {
+			return nil, err
+		}
+
+		votes = append(votes, vote)
+	}
+
+	return votes, rows.Err()
+}
+
+func (f ForumRepository) GetIgnoredAuthors(ctx context.Context, viewer string) ([]string, error) {
+	defer metrics.ObserveQuery("forum", "GetIgnoredAuthors", time.Now())
+
+	rows, err := f.stmts.ignoredAuthors.QueryContext(ctx, viewer)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get users ignored by '%v'. Error: %w", viewer, err)
+	}
+	defer rows.Close()
+
+	authors := make([]string, 0)
+	for rows.Next() {
+		var author string
+		err = rows.Scan(&author)
+		if err != nil {
+			return nil, err
+		}
+
+		authors = append(authors, author)
+	}
+
+	return authors, rows.Err()
+}
+
+func (f ForumRepository) GetSettings(ctx context.Context, slug string) (models.ForumSettings, error) {
+	defer metrics.ObserveQuery("forum", "GetSettings", time.Now())
+
+	var settings models.ForumSettings
+	err := f.stmts.getSettings.QueryRowContext(
+		ctx,
+		slug,
+	).Scan(
+		&settings.MaxPostLength, &settings.PostInterval, &settings.AllowVotes,
+		&settings.OwnerThreads, &settings.NestedReplies, &settings.MaxReplyDepth,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return forum.DefaultSettings, nil
+		}
+		return models.ForumSettings{}, fmt.Errorf("couldn't get settings of forum '%v'. Error: %w", slug, err)
+	}
+
+	return settings, nil
+}
+
+func (f ForumRepository) SetSettings(ctx context.Context, slug string, settings models.ForumSettings) error {
+	defer metrics.ObserveQuery("forum", "SetSettings", time.Now())
+
+	_, err := f.stmts.setSettings.ExecContext(
+		ctx,
+		slug, settings.MaxPostLength, settings.PostInterval, settings.AllowVotes,
+		settings.OwnerThreads, settings.NestedReplies, settings.MaxReplyDepth,
+	)
+
+	if err != nil {
+		if apperror.IsForeignKeyViolation(err) {
+			return fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return fmt.Errorf("couldn't save settings of forum '%v'. Error: %w", slug, err)
+	}
+
+	return nil
+}
+
+func (f ForumRepository) GetReplyDepth(ctx context.Context, thread int, parents []int) (int, error) {
+	defer metrics.ObserveQuery("forum", "GetReplyDepth", time.Now())
+
+	ids := make([]int64, 0, len(parents))
+	for _, id := range parents {
+		ids = append(ids, int64(id))
+	}
+
+	var depth int
+	err := f.stmts.replyDepth.QueryRowContext(
+		ctx,
+		pq.Array(ids), thread,
+	).Scan(&depth)
+
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get depth of posts in thread %v. Error: %w", thread, err)
+	}
+
+	return depth, nil
+}
+
+func (f ForumRepository) GetPrivateForums(ctx context.Context) ([]string, error) {
+	defer metrics.ObserveQuery("forum", "GetPrivateForums", time.Now())
+
+	rows, err := f.stmts.privateForums.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get private forums. Error: %w", err)
+	}
+	defer rows.Close()
+
+	slugs := make([]string, 0)
+	var slug string
+	for rows.Next() {
+		err = rows.Scan(&slug)
+		if err != nil {
+			return nil, err
+		}
+
+		slugs = append(slugs, slug)
+	}
+
+	return slugs, rows.Err()
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/migrate"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	userRepository "github.com/aanufriev/forum/internal/pkg/user/repository"
+	_ "github.com/lib/pq"
+)
+
+// newTestRepository connects to the database in FORUM_TEST_DSN, migrates it
+// and empties it, so it must not hold anything worth keeping.
+func newTestRepository(t *testing.T) (forum.Repository, *sql.DB) {
+	t.Helper()
+
+	dsn := os.Getenv("FORUM_TEST_DSN")
+	if dsn == "" {
+		t.Skip("FORUM_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := New(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repository.ClearService(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return repository, db
+}
+
+func TestGetForumsMatchesWildcardsLiterally(t *testing.T) {
+	repository, db := newTestRepository(t)
+	ctx := context.Background()
+
+	users, err := userRepository.New(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := "bob@example.com"
+	if err = users.Create(ctx, models.User{Nickname: "bob", Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	for slug, title := range map[string]string{
+		"sale":       "50% off",
+		"discount":   "50 percent off",
+		"snake":      "a_b",
+		"not-snake":  "axb",
+		"backslash":  `a\b`,
+		"no-slashes": "ab",
+	} {
+		err = repository.Create(ctx, models.Forum{Slug: slug, Title: title, User: "bob", Visibility: forum.VisibilityPublic})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for query, want := range map[string]string{
+		"50%": "sale",
+		"a_b": "snake",
+		`a\b`: "backslash",
+	} {
+		forums, err := repository.GetForums(ctx, forum.ListParams{Query: query, Sort: forum.SortTitle, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetForums(%q) error = %v", query, err)
+		}
+		if len(forums) != 1 || forums[0].Slug != want {
+			t.Errorf("GetForums(%q) = %+v, want just %v", query, forums, want)
+		}
+	}
+}
+
+func TestCreatePostsIntervalUnderConcurrency(t *testing.T) {
+	repository, db := newTestRepository(t)
+	ctx := context.Background()
+
+	users, err := userRepository.New(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := "bob@example.com"
+	if err = users.Create(ctx, models.User{Nickname: "bob", Email: &email}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = repository.Create(ctx, models.Forum{Slug: "go", Title: "Go", User: "bob", Visibility: forum.VisibilityPublic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := models.Thread{Forum: "go", Title: "hello", Author: "bob", Message: "hello"}
+	if err = repository.CreateThread(ctx, &thread); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nicknames differ in case only, so a lock keyed on the spelling would let both through.
+	authors := []string{"bob", "Bob", "BOB", "bOb"}
+	errs := make([]error, len(authors))
+	var wg sync.WaitGroup
+	for i, author := range authors {
+		wg.Add(1)
+		go func(i int, author string) {
+			defer wg.Done()
+			errs[i] = repository.CreatePosts(ctx, thread, []models.Post{{Author: author, Message: "first!"}}, time.Minute)
+		}(i, author)
+	}
+	wg.Wait()
+
+	var created int
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, forum.ErrPostTooSoon):
+			t.Errorf("CreatePosts() as %v error = %v, want nil or %v", authors[i], err, forum.ErrPostTooSoon)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%v of %v concurrent batches got through the interval, want 1", created, len(authors))
+	}
+}
@@ -1,23 +1,53 @@
 package forum
 
-import "github.com/aanufriev/forum/internal/pkg/models"
+import (
+	"context"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
 
 type Usecase interface {
-	Create(forum models.Forum) error
-	Get(slug string) (models.Forum, error)
-	CreateThread(model *models.Thread) error
-	CheckForum(slug string) (string, error)
-	GetThreads(slug string, limit string, since string, desc string) ([]models.Thread, error)
-	CreatePosts(thread models.Thread, posts []models.Post) error
-	GetThread(slugOrID string) (models.Thread, error)
-	Vote(vote models.Vote) (models.Thread, error)
-	GetPosts(slugOrID string, limit int, sort string, order string, since string) ([]models.Post, error)
-	UpdateThread(slugOrID string, thread models.Thread) (models.Thread, error)
-	GetUsersFromForum(slug string, limit int, since string, desc string) ([]models.User, error)
-	GetPostDetails(id string) (models.Post, error)
-	UpdatePost(post models.Post) (models.Post, error)
-	ClearService() error
-	GetServiceInfo() (models.ServiceInfo, error)
-	CheckThread(slugOrID string) error
-	GetThreadIDAndForum(slugOrID string) (models.Thread, error)
+	// Create puts the forum below forum.Parent when set, which takes a moderator of the parent.
+	// The forum is public unless forum.Visibility says otherwise.
+	Create(ctx context.Context, forum *models.Forum) error
+	Get(ctx context.Context, slug string) (models.Forum, error)
+	// GetDetails adds the breadcrumb to the forum and, with subtree, counts
+	// threads and posts of its subforums too.
+	GetDetails(ctx context.Context, slug string, subtree bool) (models.Forum, error)
+	GetChildren(ctx context.Context, slug string, subtree bool) ([]models.Forum, error)
+	// GetForums sorts by title unless told otherwise.
+	GetForums(ctx context.Context, params ListParams) ([]models.Forum, error)
+	// Update changes the title, which moderators may do, and the owner and the
+	// visibility, which only the owner and administrators may do. forum.User must
+	// be an existing nickname.
+	// A non-nil forum.Parent moves the forum, "" to the top level; that takes the
+	// owner and a moderator of the new parent.
+	Update(ctx context.Context, slug string, forum models.Forum) (models.Forum, error)
+	// Delete is for the owner and administrators.
+	Delete(ctx context.Context, slug string) (models.ForumDeletion, error)
+	// Reading and adding threads, posts and votes checks the forum's visibility,
+	// see permission.Usecase. Content of private forums looks missing to non-members.
+	// Adding and editing them also has to keep to the forum's settings.
+	CreateThread(ctx context.Context, model *models.Thread) error
+	GetSettings(ctx context.Context, slug string) (models.ForumSettings, error)
+	// UpdateSettings replaces the forum's settings; that takes the owner or an administrator.
+	UpdateSettings(ctx context.Context, slug string, settings models.ForumSettings) (models.ForumSettings, error)
+	CheckForum(ctx context.Context, slug string) (string, error)
+	// GetThreads and GetPosts shape the listing for viewer, see Repository.
+	GetThreads(ctx context.Context, slug string, limit string, since string, desc string, viewer string) ([]models.Thread, error)
+	CreatePosts(ctx context.Context, thread models.Thread, posts []models.Post) error
+	GetThread(ctx context.Context, slugOrID string) (models.Thread, error)
+	Vote(ctx context.Context, vote models.Vote) (models.Thread, error)
+	GetPosts(ctx context.Context, slugOrID string, limit int, sort string, order string, since string, viewer string) ([]models.Post, error)
+	UpdateThread(ctx context.Context, slugOrID string, thread models.Thread) (models.Thread, error)
+	GetUsersFromForum(ctx context.Context, slug string, limit int, since string, desc string) ([]models.User, error)
+	GetPostDetails(ctx context.Context, id string) (models.Post, error)
+	UpdatePost(ctx context.Context, post models.Post) (models.Post, error)
+	ClearService(ctx context.Context) error
+	GetServiceInfo(ctx context.Context) (models.ServiceInfo, error)
+	CheckThread(ctx context.Context, slugOrID string) error
+	GetThreadIDAndForum(ctx context.Context, slugOrID string) (models.Thread, error)
+	GetUserThreads(ctx context.Context, params ActivityParams) ([]models.Thread, error)
+	GetUserPosts(ctx context.Context, params ActivityParams) ([]models.Post, error)
+	GetUserVotes(ctx context.Context, params ActivityParams) ([]models.ThreadVote, error)
 }
@@ -1,60 +1,463 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/aanufriev/forum/internal/pkg/apperror"
 	"github.com/aanufriev/forum/internal/pkg/forum"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/go-openapi/strfmt"
+)
+
+// Page sizes of GetForums.
+const (
+	defaultForumsLimit = 100
+	maxForumsLimit     = 1000
 )
 
 type ForumUsecase struct {
 	forumRepository forum.Repository
+	permissions     permission.Usecase
 }
 
-func New(forumRepository forum.Repository) forum.Usecase {
+func New(forumRepository forum.Repository, permissions permission.Usecase) forum.Usecase {
 	return ForumUsecase{
 		forumRepository: forumRepository,
+		permissions:     permissions,
+	}
+}
+
+func (f ForumUsecase) Create(ctx context.Context, model *models.Forum) error {
+	if model.Parent != nil && *model.Parent == "" {
+		model.Parent = nil
+	}
+
+	if model.Visibility == "" {
+		model.Visibility = forum.VisibilityPublic
+	}
+	err := validVisibility(model.Visibility)
+	if err != nil {
+		return err
+	}
+
+	if model.Parent != nil {
+		parent, err := f.requireParent(ctx, *model.Parent)
+		if err != nil {
+			return err
+		}
+		model.Parent = &parent
+	}
+
+	return f.forumRepository.Create(ctx, *model)
+}
+
+// requireParent returns the slug of the forum that is to get a subforum, if ctx moderates it.
+func (f ForumUsecase) requireParent(ctx context.Context, slug string) (string, error) {
+	slug, err := f.forumRepository.CheckForum(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+
+	return slug, f.permissions.RequireModerator(ctx, slug)
+}
+
+func validVisibility(visibility string) error {
+	switch visibility {
+	case forum.VisibilityPublic, forum.VisibilityRestricted, forum.VisibilityPrivate:
+		return nil
+	}
+
+	return fmt.Errorf("visibility '%v': %w", visibility, forum.ErrInvalidVisibility)
+}
+
+// requireReader fails for content of private forums the way it would if the
+// content didn't exist, with the error format and args describe.
+func (f ForumUsecase) requireReader(ctx context.Context, slug string, format string, args ...interface{}) error {
+	err := f.permissions.RequireReader(ctx, slug)
+	if errors.Is(err, permission.ErrPrivateForum) {
+		return fmt.Errorf(format, args...)
+	}
+
+	return err
+}
+
+// unreadable lists the private forums the current actor can't read, for
+// listings and sums across forums to leave out.
+func (f ForumUsecase) unreadable(ctx context.Context) ([]string, error) {
+	private, err := f.forumRepository.GetPrivateForums(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var hidden []string
+	for _, slug := range private {
+		err = f.permissions.RequireReader(ctx, slug)
+		if errors.Is(err, permission.ErrPrivateForum) {
+			hidden = append(hidden, slug)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hidden, nil
+}
+
+func (f ForumUsecase) Get(ctx context.Context, slug string) (models.Forum, error) {
+	return f.forumRepository.Get(ctx, slug)
+}
+
+func (f ForumUsecase) GetDetails(ctx context.Context, slug string, subtree bool) (models.Forum, error) {
+	model, err := f.forumRepository.Get(ctx, slug)
+	if err != nil {
+		return models.Forum{}, err
+	}
+
+	err = f.permissions.RequireReader(ctx, model.Slug)
+	if err != nil {
+		return models.Forum{}, err
+	}
+
+	if model.Parent != nil {
+		model.Breadcrumb, err = f.forumRepository.GetBreadcrumb(ctx, model.Slug)
+		if err != nil {
+			return models.Forum{}, err
+		}
+	}
+
+	if subtree {
+		hidden, err := f.unreadable(ctx)
+		if err != nil {
+			return models.Forum{}, err
+		}
+
+		model.Threads, model.Posts, err = f.forumRepository.GetSubtreeCounters(ctx, model.Slug, hidden)
+		if err != nil {
+			return models.Forum{}, err
+		}
+	}
+
+	return model, nil
+}
+
+func (f ForumUsecase) GetForums(ctx context.Context, params forum.ListParams) ([]models.Forum, error) {
+	switch params.Sort {
+	case "":
+		params.Sort = forum.SortTitle
+	case forum.SortTitle, forum.SortCreated, forum.SortPosts, forum.SortThreads, forum.SortActivity:
+	default:
+		return nil, fmt.Errorf("sort '%v' is none of title, created, posts, threads and activity: %w", params.Sort, apperror.ErrInvalidParam)
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultForumsLimit
+	}
+	if params.Limit < 0 || params.Limit > maxForumsLimit {
+		return nil, fmt.Errorf("limit %v is not between 1 and %v: %w", params.Limit, maxForumsLimit, apperror.ErrInvalidParam)
+	}
+
+	return f.forumRepository.GetForums(ctx, params)
+}
+
+func (f ForumUsecase) GetChildren(ctx context.Context, slug string, subtree bool) ([]models.Forum, error) {
+	slug, err := f.forumRepository.CheckForum(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.permissions.RequireReader(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var hidden []string
+	if subtree {
+		hidden, err = f.unreadable(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return f.forumRepository.GetChildren(ctx, slug, subtree, hidden)
 }
 
-func (f ForumUsecase) Create(forum models.Forum) error {
-	return f.forumRepository.Create(forum)
+func (f ForumUsecase) Update(ctx context.Context, slug string, model models.Forum) (models.Forum, error) {
+	current, err := f.forumRepository.Get(ctx, slug)
+	if err != nil {
+		return models.Forum{}, err
+	}
+
+	ownerOnly := model.User != "" && !strings.EqualFold(model.User, current.User)
+	if model.Visibility != "" {
+		err = validVisibility(model.Visibility)
+		if err != nil {
+			return models.Forum{}, err
+		}
+		ownerOnly = ownerOnly || model.Visibility != current.Visibility
+	}
+	if model.Parent != nil && *model.Parent != "" {
+		parent, err := f.requireParent(ctx, *model.Parent)
+		if err != nil {
+			return models.Forum{}, err
+		}
+		model.Parent = &parent
+	}
+	if model.Parent != nil {
+		ownerOnly = true
+	}
+
+	if ownerOnly {
+		err = f.permissions.RequireOwner(ctx, current.Slug)
+	} else {
+		err = f.permissions.RequireModerator(ctx, current.Slug)
+	}
+	if err != nil {
+		return models.Forum{}, err
+	}
+
+	model.Slug = current.Slug
+	return f.forumRepository.Update(ctx, model)
 }
 
-func (f ForumUsecase) Get(slug string) (models.Forum, error) {
-	return f.forumRepository.Get(slug)
+func (f ForumUsecase) Delete(ctx context.Context, slug string) (models.ForumDeletion, error) {
+	err := f.permissions.RequireOwner(ctx, slug)
+	if err != nil {
+		return models.ForumDeletion{}, err
+	}
+
+	return f.forumRepository.Delete(ctx, slug)
 }
 
-func (f ForumUsecase) CreateThread(thread *models.Thread) error {
-	return f.forumRepository.CreateThread(thread)
+func (f ForumUsecase) CreateThread(ctx context.Context, thread *models.Thread) error {
+	err := f.permissions.RequireWriter(ctx, thread.Forum)
+	if err != nil {
+		return err
+	}
+
+	settings, err := f.forumRepository.GetSettings(ctx, thread.Forum)
+	if err != nil {
+		return err
+	}
+
+	err = checkLength(settings, thread.Message)
+	if err != nil {
+		return err
+	}
+
+	if settings.OwnerThreads {
+		model, err := f.forumRepository.Get(ctx, thread.Forum)
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(thread.Author, model.User) {
+			return fmt.Errorf("'%v' starts a thread in forum '%v' of '%v': %w", thread.Author, model.Slug, model.User, forum.ErrOwnerThreadsOnly)
+		}
+	}
+
+	return f.forumRepository.CreateThread(ctx, thread)
 }
 
-func (f ForumUsecase) CheckForum(slug string) (string, error) {
-	return f.forumRepository.CheckForum(slug)
+func (f ForumUsecase) GetSettings(ctx context.Context, slug string) (models.ForumSettings, error) {
+	slug, err := f.forumRepository.CheckForum(ctx, slug)
+	if err != nil {
+		return models.ForumSettings{}, err
+	}
+
+	err = f.permissions.RequireReader(ctx, slug)
+	if err != nil {
+		return models.ForumSettings{}, err
+	}
+
+	return f.forumRepository.GetSettings(ctx, slug)
 }
 
-func (f ForumUsecase) GetThreads(slug string, limit string, since string, desc string) ([]models.Thread, error) {
-	return f.forumRepository.GetThreads(slug, limit, since, desc)
+func (f ForumUsecase) UpdateSettings(ctx context.Context, slug string, settings models.ForumSettings) (models.ForumSettings, error) {
+	if settings.MaxPostLength < 0 || settings.PostInterval < 0 || settings.MaxReplyDepth < 0 {
+		return models.ForumSettings{}, fmt.Errorf("forum '%v': %w", slug, forum.ErrInvalidSettings)
+	}
+
+	err := f.permissions.RequireOwner(ctx, slug)
+	if err != nil {
+		return models.ForumSettings{}, err
+	}
+
+	return settings, f.forumRepository.SetSettings(ctx, slug, settings)
 }
 
-func (f ForumUsecase) CreatePosts(thread models.Thread, posts []models.Post) error {
-	return f.forumRepository.CreatePosts(thread, posts)
+// checkLength holds a post or an opening message against MaxPostLength.
+func checkLength(settings models.ForumSettings, message string) error {
+	if settings.MaxPostLength == 0 {
+		return nil
+	}
+
+	length := utf8.RuneCountInString(message)
+	if length > settings.MaxPostLength {
+		return fmt.Errorf("message of %v characters, the forum allows %v: %w", length, settings.MaxPostLength, forum.ErrPostTooLong)
+	}
+
+	return nil
 }
 
-func (f ForumUsecase) GetThread(slugOrID string) (models.Thread, error) {
+// checkPosts holds a batch of posts against the settings of the thread's forum.
+func (f ForumUsecase) checkPosts(ctx context.Context, thread models.Thread, settings models.ForumSettings, posts []models.Post) error {
+	parents := make([]int, 0)
+	for i, post := range posts {
+		err := checkLength(settings, post.Message)
+		if err != nil {
+			return fmt.Errorf("post %v: %w", i, err)
+		}
+
+		if post.Parent != 0 {
+			parents = append(parents, post.Parent)
+		}
+	}
+
+	if len(parents) != 0 && !settings.NestedReplies {
+		return fmt.Errorf("reply to post %v: %w", parents[0], forum.ErrNestedReplies)
+	}
+
+	if len(parents) != 0 && settings.MaxReplyDepth != 0 {
+		depth, err := f.forumRepository.GetReplyDepth(ctx, thread.ID, parents)
+		if err != nil {
+			return err
+		}
+
+		if depth > settings.MaxReplyDepth {
+			return fmt.Errorf("reply %v posts deep, the forum allows %v: %w", depth, settings.MaxReplyDepth, forum.ErrReplyTooDeep)
+		}
+	}
+
+	if settings.PostInterval != 0 {
+		return checkInterval(time.Duration(settings.PostInterval)*time.Second, posts)
+	}
+
+	return nil
+}
+
+// checkInterval refuses batches where an author posts more than once; the repository
+// checks earlier posts, under a lock.
+func checkInterval(interval time.Duration, posts []models.Post) error {
+	// Nicknames are case-insensitive.
+	seen := make(map[string]bool, len(posts))
+	for _, post := range posts {
+		key := strings.ToLower(post.Author)
+		if seen[key] {
+			return fmt.Errorf("'%v' posts twice at once, the forum wants %v between posts: %w", post.Author, interval, forum.ErrPostTooSoon)
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func (f ForumUsecase) CheckForum(ctx context.Context, slug string) (string, error) {
+	return f.forumRepository.CheckForum(ctx, slug)
+}
+
+func (f ForumUsecase) GetThreads(ctx context.Context, slug string, limit string, since string, desc string, viewer string) ([]models.Thread, error) {
+	if since != "" {
+		if _, err := strfmt.ParseDateTime(since); err != nil {
+			return nil, fmt.Errorf("since '%v' is not a date: %w", since, apperror.ErrInvalidParam)
+		}
+	}
+
+	err := f.permissions.RequireReader(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.forumRepository.GetThreads(ctx, slug, limit, since, desc, viewer)
+}
+
+func (f ForumUsecase) CreatePosts(ctx context.Context, thread models.Thread, posts []models.Post) error {
+	err := f.permissions.RequireWriter(ctx, thread.Forum)
+	if err != nil {
+		return err
+	}
+
+	settings, err := f.forumRepository.GetSettings(ctx, thread.Forum)
+	if err != nil {
+		return err
+	}
+
+	err = f.checkPosts(ctx, thread, settings, posts)
+	if err != nil {
+		return err
+	}
+
+	return f.forumRepository.CreatePosts(ctx, thread, posts, time.Duration(settings.PostInterval)*time.Second)
+}
+
+func (f ForumUsecase) GetThread(ctx context.Context, slugOrID string) (models.Thread, error) {
 	id, err := strconv.Atoi(slugOrID)
 	if err != nil {
-		return f.forumRepository.GetThreadBySlug(slugOrID)
+		thread, err := f.forumRepository.GetThreadBySlug(ctx, slugOrID)
+		if err != nil {
+			return models.Thread{}, err
+		}
+
+		return thread, f.requireReader(ctx, thread.Forum, "can't find thread with slug '%v': %w", slugOrID, forum.ErrThreadDoesntExists)
+	}
+
+	thread, err := f.forumRepository.GetThreadByID(ctx, id)
+	if err != nil {
+		return models.Thread{}, err
 	}
 
-	return f.forumRepository.GetThreadByID(id)
+	return thread, f.requireReader(ctx, thread.Forum, "can't find thread with id %v: %w", id, forum.ErrThreadDoesntExists)
 }
 
-func (f ForumUsecase) Vote(vote models.Vote) (models.Thread, error) {
-	return f.forumRepository.Vote(vote)
+func (f ForumUsecase) Vote(ctx context.Context, vote models.Vote) (models.Thread, error) {
+	if vote.Voice != 1 && vote.Voice != -1 {
+		return models.Thread{}, fmt.Errorf("voice %v: %w", vote.Voice, forum.ErrInvalidVoice)
+	}
+
+	thread, err := f.GetThreadIDAndForum(ctx, vote.Slug)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	err = f.permissions.RequireWriter(ctx, thread.Forum)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	settings, err := f.forumRepository.GetSettings(ctx, thread.Forum)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if !settings.AllowVotes {
+		return models.Thread{}, fmt.Errorf("vote on thread %v in forum '%v': %w", thread.ID, thread.Forum, forum.ErrVotesDisabled)
+	}
+
+	return f.forumRepository.Vote(ctx, vote)
 }
 
-func (f ForumUsecase) GetPosts(slugOrID string, limit int, sort string, order string, since string) ([]models.Post, error) {
+// GetPosts drops the posts of authors viewer ignores from flat listings. Trees keep
+// them as collapsed placeholders, so replies stay in place and since keeps working.
+func (f ForumUsecase) GetPosts(ctx context.Context, slugOrID string, limit int, sort string, order string, since string, viewer string) ([]models.Post, error) {
+	if since != "" {
+		if _, err := strconv.Atoi(since); err != nil {
+			return nil, fmt.Errorf("since '%v' is not a post id: %w", since, apperror.ErrInvalidParam)
+		}
+	}
+
+	_, err := f.GetThreadIDAndForum(ctx, slugOrID)
+	if err != nil {
+		return nil, err
+	}
+
 	switch order {
 	case "true":
 		order = "DESC"
@@ -62,19 +465,65 @@ func (f ForumUsecase) GetPosts(slugOrID string, limit int, sort string, order st
 		order = "ASC"
 	}
 
+	var posts []models.Post
 	switch sort {
-	case "flat":
-		return f.forumRepository.GetPosts(slugOrID, limit, order, since)
 	case "tree":
-		return f.forumRepository.GetPostsTree(slugOrID, limit, order, since)
+		posts, err = f.forumRepository.GetPostsTree(ctx, slugOrID, limit, order, since)
 	case "parent_tree":
-		return f.forumRepository.GetPostsParentTree(slugOrID, limit, order, since)
+		posts, err = f.forumRepository.GetPostsParentTree(ctx, slugOrID, limit, order, since)
 	default:
-		return f.forumRepository.GetPosts(slugOrID, limit, order, since)
+		return f.forumRepository.GetPosts(ctx, slugOrID, limit, order, since, viewer)
 	}
+
+	if err != nil || viewer == "" {
+		return posts, err
+	}
+
+	ignored, err := f.forumRepository.GetIgnoredAuthors(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return collapseIgnored(posts, ignored), nil
 }
 
-func (f ForumUsecase) UpdateThread(slugOrID string, thread models.Thread) (models.Thread, error) {
+func collapseIgnored(posts []models.Post, ignored []string) []models.Post {
+	if len(ignored) == 0 {
+		return posts
+	}
+
+	// Nicknames are case-insensitive.
+	authors := make(map[string]bool, len(ignored))
+	for _, author := range ignored {
+		authors[strings.ToLower(author)] = true
+	}
+
+	for i := range posts {
+		if authors[strings.ToLower(posts[i].Author)] {
+			posts[i].Message = ""
+			posts[i].Ignored = true
+		}
+	}
+
+	return posts
+}
+
+func (f ForumUsecase) UpdateThread(ctx context.Context, slugOrID string, thread models.Thread) (models.Thread, error) {
+	current, err := f.GetThread(ctx, slugOrID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	err = f.permissions.RequireAuthor(ctx, current.Author, current.Forum)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	err = f.checkEdit(ctx, current.Forum, thread.Message)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
 	thread.Slug = &slugOrID
 	id, err := strconv.Atoi(slugOrID)
 	if err != nil {
@@ -82,46 +531,149 @@ func (f ForumUsecase) UpdateThread(slugOrID string, thread models.Thread) (model
 	}
 
 	thread.ID = id
-	return f.forumRepository.UpdateThread(thread)
+	return f.forumRepository.UpdateThread(ctx, thread)
 }
 
-func (f ForumUsecase) GetUsersFromForum(slug string, limit int, since string, desc string) ([]models.User, error) {
+func (f ForumUsecase) GetUsersFromForum(ctx context.Context, slug string, limit int, since string, desc string) ([]models.User, error) {
 	switch desc {
 	case "true":
 		desc = "DESC"
 	case "false":
 		desc = "ASC"
 	}
-	return f.forumRepository.GetUsersFromForum(slug, limit, since, desc)
+
+	err := f.permissions.RequireReader(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.forumRepository.GetUsersFromForum(ctx, slug, limit, since, desc)
 }
 
-func (f ForumUsecase) GetPostDetails(id string) (models.Post, error) {
-	return f.forumRepository.GetPostDetails(id)
+func (f ForumUsecase) GetPostDetails(ctx context.Context, id string) (models.Post, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return models.Post{}, fmt.Errorf("post id '%v' is not a number: %w", id, apperror.ErrInvalidParam)
+	}
+
+	post, err := f.forumRepository.GetPostDetails(ctx, id)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = f.requireReader(ctx, post.Forum, "can't find post with id %v: %w", id, forum.ErrPostDoesntExists)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	return post, nil
 }
 
-func (f ForumUsecase) UpdatePost(post models.Post) (models.Post, error) {
-	return f.forumRepository.UpdatePost(post)
+func (f ForumUsecase) UpdatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	current, err := f.forumRepository.GetPostDetails(ctx, strconv.Itoa(post.ID))
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = f.permissions.RequireAuthor(ctx, current.Author, current.Forum)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	err = f.checkEdit(ctx, current.Forum, post.Message)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	return f.forumRepository.UpdatePost(ctx, post)
+}
+
+// checkEdit keeps edits to the MaxPostLength of the forum, too.
+func (f ForumUsecase) checkEdit(ctx context.Context, slug string, message string) error {
+	settings, err := f.forumRepository.GetSettings(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	return checkLength(settings, message)
 }
 
-func (f ForumUsecase) ClearService() error {
-	return f.forumRepository.ClearService()
+func (f ForumUsecase) ClearService(ctx context.Context) error {
+	err := f.permissions.RequireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return f.forumRepository.ClearService(ctx)
 }
 
-func (f ForumUsecase) GetServiceInfo() (models.ServiceInfo, error) {
-	return f.forumRepository.GetServiceInfo()
+func (f ForumUsecase) GetServiceInfo(ctx context.Context) (models.ServiceInfo, error) {
+	return f.forumRepository.GetServiceInfo(ctx)
 }
 
-func (f ForumUsecase) CheckThread(slugOrID string) error {
+func (f ForumUsecase) CheckThread(ctx context.Context, slugOrID string) error {
 	id, err := strconv.Atoi(slugOrID)
 	if err != nil {
-		_, err = f.forumRepository.CheckThreadBySlug(slugOrID)
+		_, err = f.forumRepository.CheckThreadBySlug(ctx, slugOrID)
 		return err
 	}
 
-	_, err = f.forumRepository.CheckThreadByID(id)
+	_, err = f.forumRepository.CheckThreadByID(ctx, id)
 	return err
 }
 
-func (f ForumUsecase) GetThreadIDAndForum(slugOrID string) (models.Thread, error) {
-	return f.forumRepository.GetThreadIDAndForum(slugOrID)
+func (f ForumUsecase) GetThreadIDAndForum(ctx context.Context, slugOrID string) (models.Thread, error) {
+	thread, err := f.forumRepository.GetThreadIDAndForum(ctx, slugOrID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	return thread, f.requireReader(ctx, thread.Forum, "can't find thread '%v': %w", slugOrID, forum.ErrThreadDoesntExists)
+}
+
+func (f ForumUsecase) GetUserThreads(ctx context.Context, params forum.ActivityParams) ([]models.Thread, error) {
+	if params.Since != "" {
+		if _, err := strfmt.ParseDateTime(params.Since); err != nil {
+			return nil, fmt.Errorf("since '%v' is not a date: %w", params.Since, apperror.ErrInvalidParam)
+		}
+	}
+
+	hidden, err := f.unreadable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params.Hidden = hidden
+
+	return f.forumRepository.GetUserThreads(ctx, params)
+}
+
+func (f ForumUsecase) GetUserPosts(ctx context.Context, params forum.ActivityParams) ([]models.Post, error) {
+	if params.Since != "" {
+		if _, err := strconv.Atoi(params.Since); err != nil {
+			return nil, fmt.Errorf("since '%v' is not a post id: %w", params.Since, apperror.ErrInvalidParam)
+		}
+	}
+
+	hidden, err := f.unreadable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params.Hidden = hidden
+
+	return f.forumRepository.GetUserPosts(ctx, params)
+}
+
+func (f ForumUsecase) GetUserVotes(ctx context.Context, params forum.ActivityParams) ([]models.ThreadVote, error) {
+	if params.Since != "" {
+		if _, err := strconv.Atoi(params.Since); err != nil {
+			return nil, fmt.Errorf("since '%v' is not a thread id: %w", params.Since, apperror.ErrInvalidParam)
+		}
+	}
+
+	hidden, err := f.unreadable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params.Hidden = hidden
+
+	return f.forumRepository.GetUserVotes(ctx, params)
 }
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+func TestCollapseIgnored(t *testing.T) {
+	page := func() []models.Post {
+		return []models.Post{
+			{ID: 1, Author: "Bob", Message: "first"},
+			{ID: 2, Parent: 1, Author: "alice", Message: "reply"},
+			{ID: 3, Parent: 2, Author: "carol", Message: "another reply"},
+		}
+	}
+
+	got := collapseIgnored(page(), []string{"bob", "CAROL", "dave"})
+	want := []models.Post{
+		{ID: 1, Author: "Bob", Ignored: true},
+		{ID: 2, Parent: 1, Author: "alice", Message: "reply"},
+		{ID: 3, Parent: 2, Author: "carol", Ignored: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collapseIgnored() = %+v, want %+v", got, want)
+	}
+
+	if got := collapseIgnored(page(), nil); !reflect.DeepEqual(got, page()) {
+		t.Errorf("without ignores collapseIgnored() = %+v, want the page as is", got)
+	}
+}
+
+func TestCheckInterval(t *testing.T) {
+	check := func(authors ...string) error {
+		posts := make([]models.Post, 0, len(authors))
+		for _, author := range authors {
+			posts = append(posts, models.Post{Author: author})
+		}
+		return checkInterval(time.Minute, posts)
+	}
+
+	if err := check("alice", "carol"); err != nil {
+		t.Errorf("different authors: %v", err)
+	}
+
+	err := check("carol", "dave", "CAROL")
+	if !errors.Is(err, forum.ErrPostTooSoon) || !strings.Contains(err.Error(), "posts twice at once") {
+		t.Errorf("carol posting twice in a batch: error = %v, want %v", err, forum.ErrPostTooSoon)
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	tests := []struct {
+		limit   int
+		message string
+		tooLong bool
+	}{
+		{0, strings.Repeat("a", 100000), false},
+		{5, "", false},
+		{5, "abcde", false},
+		{5, "abcdef", true},
+		// Characters count, not bytes.
+		{5, "ёжики", false},
+		{5, "ёжиков", true},
+	}
+
+	for _, tt := range tests {
+		err := checkLength(models.ForumSettings{MaxPostLength: tt.limit}, tt.message)
+		if tt.tooLong && !errors.Is(err, forum.ErrPostTooLong) || !tt.tooLong && err != nil {
+			t.Errorf("checkLength(%v, %q) error = %v, want too long %v", tt.limit, tt.message, err, tt.tooLong)
+		}
+	}
+}
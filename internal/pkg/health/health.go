@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/response"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var ErrShuttingDown = fmt.Errorf("server is shutting down")
+
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker answers liveness and readiness probes.
+// Readiness runs every registered check, each bounded by timeout.
+type Checker struct {
+	checks       []check
+	timeout      time.Duration
+	shuttingDown int32
+}
+
+func New(timeout time.Duration) *Checker {
+	c := &Checker{
+		timeout: timeout,
+	}
+	c.Add("shutdown", c.checkShutdown)
+
+	return c
+}
+
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{
+		name: name,
+		fn:   fn,
+	})
+}
+
+// SetShuttingDown makes readiness fail so no new traffic is routed here.
+func (c *Checker) SetShuttingDown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+func (c *Checker) checkShutdown(ctx context.Context) error {
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		return ErrShuttingDown
+	}
+
+	return nil
+}
+
+func (c *Checker) Liveness(ctx *fasthttp.RequestCtx) {
+	response.JSON(ctx, http.StatusOK, models.HealthReport{
+		Status: StatusOK,
+	})
+}
+
+func (c *Checker) Readiness(ctx *fasthttp.RequestCtx) {
+	report := models.HealthReport{
+		Status: StatusOK,
+		Checks: make([]models.HealthCheck, 0, len(c.checks)),
+	}
+
+	for _, check := range c.checks {
+		result := c.run(check)
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	response.JSON(ctx, status, report)
+}
+
+func (c *Checker) run(check check) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.fn(ctx)
+
+	result := models.HealthCheck{
+		Name:     check.name,
+		Status:   StatusOK,
+		Duration: time.Since(start).String(),
+	}
+
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func DatabaseCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Outbox writes every message into a maildir instead of sending it,
+// for development and for tests that need to read the mail back.
+type Outbox struct {
+	dir  string
+	from string
+	seq  *uint64
+}
+
+// NewOutbox creates the tmp, new and cur subdirectories of dir if they are missing.
+func NewOutbox(dir string, from string) (Outbox, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0o700)
+		if err != nil {
+			return Outbox{}, fmt.Errorf("couldn't create outbox '%v'. Error: %w", dir, err)
+		}
+	}
+
+	return Outbox{
+		dir:  dir,
+		from: from,
+		seq:  new(uint64),
+	}, nil
+}
+
+// Send writes the message into tmp and then moves it into new,
+// so readers of new never see a partial file.
+func (o Outbox) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." +
+		strconv.Itoa(os.Getpid()) + "_" + strconv.FormatUint(atomic.AddUint64(o.seq, 1), 10) + "." + hostname
+
+	tmp := filepath.Join(o.dir, "tmp", name)
+	err = ioutil.WriteFile(tmp, format(o.from, message), 0o600)
+	if err != nil {
+		return fmt.Errorf("couldn't write message to '%v'. Error: %w", message.To, err)
+	}
+
+	err = os.Rename(tmp, filepath.Join(o.dir, "new", name))
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("couldn't deliver message to '%v'. Error: %w", message.To, err)
+	}
+
+	return nil
+}
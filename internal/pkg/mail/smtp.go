@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+)
+
+type SMTP struct {
+	addr     string
+	from     string
+	envelope string
+	auth     smtp.Auth
+}
+
+// NewSMTP sends through the server at addr (host:port), using PLAIN
+// authentication when username is set. The server must offer STARTTLS for that.
+func NewSMTP(addr string, username string, password string, from string) (SMTP, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return SMTP{}, fmt.Errorf("smtp address '%v' is not host:port. Error: %w", addr, err)
+	}
+
+	envelope, err := netmail.ParseAddress(from)
+	if err != nil {
+		return SMTP{}, fmt.Errorf("sender '%v' is not an email address. Error: %w", from, err)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return SMTP{
+		addr:     addr,
+		from:     from,
+		envelope: envelope.Address,
+		auth:     auth,
+	}, nil
+}
+
+// Send can't be interrupted once the connection is made, ctx is only checked before.
+func (s SMTP) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := smtp.SendMail(s.addr, s.auth, s.envelope, []string{message.To}, format(s.from, message))
+	if err != nil {
+		return fmt.Errorf("couldn't send message to '%v'. Error: %w", message.To, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+const namespace = "forum"
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	requestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of repository methods.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+)
+
+var (
+	dbStatsMu        sync.Mutex
+	dbStatsCollector prometheus.Collector
+)
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() fasthttp.RequestHandler {
+	return fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+}
+
+// Instrument records count, latency and in-flight gauge for a route.
+// route must be the router pattern, not the actual path, to keep label cardinality bounded.
+func Instrument(route string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		requestsInFlight.Inc()
+
+		defer func() {
+			requestsInFlight.Dec()
+
+			method := string(ctx.Method())
+			requestsTotal.WithLabelValues(method, route, strconv.Itoa(ctx.Response.StatusCode())).Inc()
+			requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		}()
+
+		next(ctx)
+	}
+}
+
+// ObserveQuery is meant to be deferred at the top of a repository method:
+//
+//	defer metrics.ObserveQuery("forum", "GetThreads", time.Now())
+func ObserveQuery(repository string, method string, start time.Time) {
+	queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+// RegisterDB exports sql.DBStats of db, replacing a previously registered pool.
+func RegisterDB(db *sql.DB) {
+	dbStatsMu.Lock()
+	defer dbStatsMu.Unlock()
+
+	if dbStatsCollector != nil {
+		prometheus.Unregister(dbStatsCollector)
+	}
+
+	dbStatsCollector = collectors.NewDBStatsCollector(db, namespace)
+	prometheus.MustRegister(dbStatsCollector)
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/buaazp/fasthttprouter"
+	"github.com/valyala/fasthttp"
+)
+
+// Router instruments every route registered through it.
+type Router struct {
+	*fasthttprouter.Router
+}
+
+func NewRouter() Router {
+	return Router{
+		Router: fasthttprouter.New(),
+	}
+}
+
+func (r Router) GET(path string, handle fasthttp.RequestHandler) {
+	r.Router.Handle(http.MethodGet, path, Instrument(path, handle))
+}
+
+func (r Router) POST(path string, handle fasthttp.RequestHandler) {
+	r.Router.Handle(http.MethodPost, path, Instrument(path, handle))
+}
+
+func (r Router) DELETE(path string, handle fasthttp.RequestHandler) {
+	r.Router.Handle(http.MethodDelete, path, Instrument(path, handle))
+}
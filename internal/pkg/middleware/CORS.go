@@ -2,20 +2,44 @@ package middleware
 
 import (
 	"net/http"
+
+	"github.com/valyala/fasthttp"
 )
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		if r.Method == "OPTIONS" {
-			w.Header().Add("Content-Type", "text/plain")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, DELETE, PUT")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token")
-			w.Header().Set("Access-Control-Max-Age", "86400")
-			w.WriteHeader(http.StatusNoContent)
-			return
+// CORS allows cross-origin requests from the listed origins only.
+// An origin of "*" allows any origin, but without credentials; only
+// listed origins may send cookies and authorization headers.
+func CORS(allowedOrigins []string) Middleware {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			origin := string(ctx.Request.Header.Peek("Origin"))
+			if origin == "" || !(allowed[origin] || allowed["*"]) {
+				next(ctx)
+				return
+			}
+
+			if allowed[origin] {
+				ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+				ctx.Response.Header.Set("Access-Control-Allow-Credentials", "true")
+				ctx.Response.Header.Add("Vary", "Origin")
+			} else {
+				ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
+			}
+			if ctx.IsOptions() {
+				ctx.SetContentType("text/plain")
+				ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, DELETE, PUT")
+				ctx.Response.Header.Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Request-ID, Authorization")
+				ctx.Response.Header.Set("Access-Control-Max-Age", "86400")
+				ctx.SetStatusCode(http.StatusNoContent)
+				return
+			}
+
+			next(ctx)
 		}
-		next.ServeHTTP(w, r)
-	})
+	}
 }
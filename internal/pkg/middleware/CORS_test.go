@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// corsRequest runs a request from origin through CORS and reports whether it
+// reached the handler.
+func corsRequest(allowed []string, method, origin string) (*fasthttp.RequestCtx, bool) {
+	reached := false
+	handler := CORS(allowed)(func(ctx *fasthttp.RequestCtx) {
+		reached = true
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	if origin != "" {
+		ctx.Request.Header.Set("Origin", origin)
+	}
+	handler(ctx)
+
+	return ctx, reached
+}
+
+func TestCORS(t *testing.T) {
+	listed := []string{"https://a.example"}
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		method      string
+		origin      string
+		wantOrigin  string
+		credentials bool
+		wantStatus  int
+	}{
+		{"same origin", listed, http.MethodGet, "", "", false, http.StatusOK},
+		{"listed origin", listed, http.MethodGet, "https://a.example", "https://a.example", true, http.StatusOK},
+		{"unlisted origin", listed, http.MethodGet, "https://b.example", "", false, http.StatusOK},
+		{"any origin", []string{"*"}, http.MethodGet, "https://b.example", "*", false, http.StatusOK},
+		{"listed next to any", []string{"*", "https://a.example"}, http.MethodGet, "https://a.example", "https://a.example", true, http.StatusOK},
+		{"preflight", listed, http.MethodOptions, "https://a.example", "https://a.example", true, http.StatusNoContent},
+		{"preflight from any origin", []string{"*"}, http.MethodOptions, "https://b.example", "*", false, http.StatusNoContent},
+		{"preflight from unlisted origin", listed, http.MethodOptions, "https://b.example", "", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, reached := corsRequest(tt.allowed, tt.method, tt.origin)
+
+			if got := string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")) == "true"; got != tt.credentials {
+				t.Errorf("credentials allowed = %v, want %v", got, tt.credentials)
+			}
+			if got := ctx.Response.StatusCode(); got != tt.wantStatus {
+				t.Errorf("status = %v, want %v", got, tt.wantStatus)
+			}
+			// Preflights of allowed origins are answered by CORS itself.
+			if wantReached := tt.wantStatus != http.StatusNoContent; reached != wantReached {
+				t.Errorf("handler reached = %v, want %v", reached, wantReached)
+			}
+		})
+	}
+}
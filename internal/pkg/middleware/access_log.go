@@ -1,31 +1,40 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
+	"time"
 
 	"github.com/aanufriev/forum/configs"
-
-	"github.com/lithammer/shortuuid"
 	"github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
 )
 
-type RequestID string
+// AccessLog logs every request once it is answered. A panic is logged as the 500
+// Recover, further out, answers with, and passed on to it.
+func AccessLog(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		defer func() {
+			r := recover()
+			status := ctx.Response.StatusCode()
+			if r != nil {
+				status = http.StatusInternalServerError
+			}
 
-func log(r *http.Request) {
-	logrus.WithFields(logrus.Fields{
-		configs.RequestID: r.Context().Value(configs.RequestID),
-		"method":          r.Method,
-		"remote_addr":     r.RemoteAddr,
-	}).Info(r.URL.Path)
-}
+			logrus.WithFields(logrus.Fields{
+				configs.RequestID: ctx.UserValue(configs.RequestID),
+				"method":          string(ctx.Method()),
+				"remote_addr":     ctx.RemoteAddr().String(),
+				"status":          status,
+				"latency":         time.Since(start).String(),
+				"size":            len(ctx.Response.Body()),
+			}).Info(string(ctx.Path()))
+
+			if r != nil {
+				panic(r)
+			}
+		}()
 
-func AccessLog(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqID := shortuuid.New()
-		ctx := context.WithValue(r.Context(), RequestID(configs.RequestID), reqID)
-		r = r.WithContext(ctx)
-		log(r)
-		next.ServeHTTP(w, r)
-	})
+		next(ctx)
+	}
 }
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/response"
+	"github.com/valyala/fasthttp"
+)
+
+// Authenticate resolves a bearer token to the nickname the request acts as
+// and stores it in the request user values. Requests without a token pass
+// through anonymously; a token that doesn't resolve is answered with 401.
+func Authenticate(authenticate func(ctx context.Context, token string) (string, error), timeout time.Duration) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			token, ok := auth.BearerToken(string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization)))
+			if !ok {
+				next(ctx)
+				return
+			}
+
+			reqCtx, cancel := requestctx.New(ctx, timeout)
+			nickname, err := authenticate(reqCtx, token)
+			cancel()
+
+			if err != nil {
+				response.Error(ctx, err)
+				return
+			}
+
+			ctx.SetUserValue(configs.Actor, nickname)
+			next(ctx)
+		}
+	}
+}
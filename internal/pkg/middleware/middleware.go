@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/valyala/fasthttp"
+
+type Middleware func(next fasthttp.RequestHandler) fasthttp.RequestHandler
+
+// Chain wraps handler so that the first middleware is the outermost one.
+func Chain(handler fasthttp.RequestHandler, middlewares ...Middleware) fasthttp.RequestHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return func(ctx *fasthttp.RequestCtx) {
+				order = append(order, name)
+				next(ctx)
+				order = append(order, "/"+name)
+			}
+		}
+	}
+	handler := func(ctx *fasthttp.RequestCtx) {
+		order = append(order, "handler")
+	}
+
+	Chain(handler, record("a"), record("b"), record("c"))(&fasthttp.RequestCtx{})
+	want := []string{"a", "b", "c", "handler", "/c", "/b", "/a"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+
+	order = nil
+	Chain(handler)(&fasthttp.RequestCtx{})
+	if !reflect.DeepEqual(order, []string{"handler"}) {
+		t.Errorf("without middlewares order = %v, want just the handler", order)
+	}
+}
+
+func TestPanicAnswersThroughTheChain(t *testing.T) {
+	var log bytes.Buffer
+	logrus.SetOutput(&log)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	handler := Chain(func(ctx *fasthttp.RequestCtx) {
+		panic("boom")
+	}, RequestID, Recover, AccessLog, CORS([]string{"https://example.com"}))
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.Set("Origin", "https://example.com")
+	handler(&ctx)
+
+	if ctx.Response.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("status = %v, want %v", ctx.Response.StatusCode(), http.StatusInternalServerError)
+	}
+	if origin := string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")); origin != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the origin", origin)
+	}
+	if !strings.Contains(log.String(), "status=500") {
+		t.Errorf("access log = %q, want it to record the 500", log.String())
+	}
+}
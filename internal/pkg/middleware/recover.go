@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/response"
+	"github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+// Recover turns a panic in a handler into a JSON 500 response.
+func Recover(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		defer func() {
+			if r := recover(); r != nil {
+				logrus.WithFields(logrus.Fields{
+					configs.RequestID: ctx.UserValue(configs.RequestID),
+					"panic":           r,
+					"stack":           string(debug.Stack()),
+				}).Error("handler panicked")
+
+				ctx.Response.ResetBody()
+				response.JSON(ctx, http.StatusInternalServerError, models.Message{
+					Text: "Internal server error",
+					Code: response.InternalErrorCode,
+				})
+			}
+		}()
+
+		next(ctx)
+	}
+}
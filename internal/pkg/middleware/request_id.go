@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/aanufriev/forum/configs"
+	"github.com/lithammer/shortuuid"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestID reuses the caller's X-Request-ID or generates a new one,
+// stores it in the request user values and echoes it in the response.
+func RequestID(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		reqID := string(ctx.Request.Header.Peek(RequestIDHeader))
+		if reqID == "" || len(reqID) > maxRequestIDLength {
+			reqID = shortuuid.New()
+		}
+
+		ctx.SetUserValue(configs.RequestID, reqID)
+		ctx.Response.Header.Set(RequestIDHeader, reqID)
+		next(ctx)
+	}
+}
@@ -0,0 +1,345 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID serialises migrations run by concurrently starting instances.
+const lockID = 7355608
+
+var (
+	ErrSchemaBehind  = fmt.Errorf("database schema is behind, run 'migrate up'")
+	ErrSchemaAhead   = fmt.Errorf("database schema is newer than this binary")
+	ErrNothingToUndo = fmt.Errorf("no migrations applied")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+func load() ([]Migration, error) {
+	entries, err := files.ReadDir("sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name '%v'", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		body, err := files.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %v has two names: '%v' and '%v'", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %v_%v must have both up and down files", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be consecutive, %v is missing", i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Latest is the version the binary expects the database to be at.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations(
+			version INT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		)`,
+	)
+
+	if err != nil {
+		return fmt.Errorf("couldn't create schema_migrations. Error: %w", err)
+	}
+
+	return nil
+}
+
+// Current returns the highest applied version, 0 for an empty database.
+// It doesn't create schema_migrations, so it is cheap enough for readiness probes.
+func (m *Migrator) Current(ctx context.Context) (int, error) {
+	var exists bool
+	err := m.db.QueryRowContext(
+		ctx,
+		"SELECT to_regclass('schema_migrations') IS NOT NULL",
+	).Scan(&exists)
+
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get schema version. Error: %w", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	err = m.db.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(max(version), 0) FROM schema_migrations",
+	).Scan(&version)
+
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get schema version. Error: %w", err)
+	}
+
+	return version, nil
+}
+
+// Check fails unless the database is exactly at the latest version.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version < m.Latest():
+		return fmt.Errorf("version %v, want %v: %w", version, m.Latest(), ErrSchemaBehind)
+	case version > m.Latest():
+		return fmt.Errorf("version %v, want %v: %w", version, m.Latest(), ErrSchemaAhead)
+	}
+
+	return nil
+}
+
+// Up applies every pending migration, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	err := m.ensureTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0)
+	for _, migration := range m.migrations {
+		done, err := m.apply(ctx, migration)
+		if err != nil {
+			return applied, err
+		}
+
+		if done {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) (bool, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockID)
+	if err != nil {
+		return false, fmt.Errorf("couldn't lock schema_migrations. Error: %w", err)
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)",
+		migration.Version,
+	).Scan(&exists)
+
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, migration.Up)
+	if err != nil {
+		return false, fmt.Errorf("couldn't apply migration %v_%v. Error: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+		migration.Version, migration.Name,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	err := m.ensureTable(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Migration{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockID)
+	if err != nil {
+		return Migration{}, fmt.Errorf("couldn't lock schema_migrations. Error: %w", err)
+	}
+
+	var version int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(max(version), 0) FROM schema_migrations",
+	).Scan(&version)
+
+	if err != nil {
+		return Migration{}, err
+	}
+
+	if version == 0 {
+		return Migration{}, ErrNothingToUndo
+	}
+
+	if version > m.Latest() {
+		return Migration{}, fmt.Errorf("version %v: %w", version, ErrSchemaAhead)
+	}
+
+	migration := m.migrations[version-1]
+	_, err = tx.ExecContext(ctx, migration.Down)
+	if err != nil {
+		return Migration{}, fmt.Errorf("couldn't roll back migration %v_%v. Error: %w", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	return migration, tx.Commit()
+}
+
+// Status lists every known migration with the time it was applied, if it was.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	err := m.ensureTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+
+		err = rows.Scan(&version, &at)
+		if err != nil {
+			return nil, err
+		}
+
+		appliedAt[version] = at
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS forum_user CASCADE;
+DROP TABLE IF EXISTS thread_vote CASCADE;
+DROP TABLE IF EXISTS posts CASCADE;
+DROP TABLE IF EXISTS threads CASCADE;
+DROP TABLE IF EXISTS forums CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+
+DROP FUNCTION IF EXISTS insert_thread_votes();
+DROP FUNCTION IF EXISTS update_thread_votes();
+DROP FUNCTION IF EXISTS set_post_path();
+DROP FUNCTION IF EXISTS update_forum_threads();
+DROP FUNCTION IF EXISTS add_forum_user();
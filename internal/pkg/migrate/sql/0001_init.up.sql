@@ -1,29 +1,9 @@
+-- Initial schema. Written to be idempotent so that databases created
+-- by the former configs/init.sql can be adopted without data loss.
 CREATE EXTENSION IF NOT EXISTS CITEXT;
 
-DROP TABLE IF EXISTS forum_user CASCADE;
-DROP TABLE IF EXISTS votes CASCADE;
-DROP TABLE IF EXISTS posts CASCADE;
-DROP TABLE IF EXISTS threads CASCADE;
-DROP TABLE IF EXISTS forums CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
 
-DROP FUNCTION IF EXISTS insert_thread_votes();
-DROP FUNCTION IF EXISTS update_thread_votes();
-DROP FUNCTION IF EXISTS set_post_path();
-DROP FUNCTION IF EXISTS update_forum_threads();
-DROP FUNCTION IF EXISTS update_forum_posts();
-DROP FUNCTION IF EXISTS add_forum_user();
-
-DROP TRIGGER IF EXISTS insert_thread_votes ON thread_vote;
-DROP TRIGGER IF EXISTS update_thread_votes ON thread_vote;
-DROP TRIGGER IF EXISTS set_post_path ON posts;
-DROP TRIGGER IF EXISTS update_forum_threads ON threads;
-DROP TRIGGER IF EXISTS update_forum_posts ON posts;
-DROP TRIGGER IF EXISTS add_forum_user_new_thread ON threads;
-DROP TRIGGER IF EXISTS add_forum_user_new_post ON posts;
-
-
-CREATE UNLOGGED TABLE users(
+CREATE UNLOGGED TABLE IF NOT EXISTS users(
     id SERIAL PRIMARY KEY,
     nickname CITEXT UNIQUE NOT NULL,
     fullname CITEXT NOT NULL,
@@ -31,11 +11,11 @@ CREATE UNLOGGED TABLE users(
     email CITEXT UNIQUE NOT NULL
 );
 
-CREATE INDEX index_users_nickname_hash ON users USING HASH (nickname);
-CREATE INDEX index_users_email_hash ON users USING HASH (email);
+CREATE INDEX IF NOT EXISTS index_users_nickname_hash ON users USING HASH (nickname);
+CREATE INDEX IF NOT EXISTS index_users_email_hash ON users USING HASH (email);
 
 
-CREATE UNLOGGED TABLE forums(
+CREATE UNLOGGED TABLE IF NOT EXISTS forums(
     id SERIAL PRIMARY KEY,
     title TEXT NOT NULL,
     user_nickname CITEXT NOT NULL,
@@ -46,12 +26,12 @@ CREATE UNLOGGED TABLE forums(
     FOREIGN KEY (user_nickname) REFERENCES users (nickname)
 );
 
-CREATE INDEX index_forums ON forums (slug, title, user_nickname, post_count, thread_count);
-CREATE INDEX index_forums_slug_hash ON forums USING hash (slug);
-CREATE INDEX index_forums_users_foreign ON forums (user_nickname);
+CREATE INDEX IF NOT EXISTS index_forums ON forums (slug, title, user_nickname, post_count, thread_count);
+CREATE INDEX IF NOT EXISTS index_forums_slug_hash ON forums USING hash (slug);
+CREATE INDEX IF NOT EXISTS index_forums_users_foreign ON forums (user_nickname);
 
 
-CREATE UNLOGGED TABLE threads(
+CREATE UNLOGGED TABLE IF NOT EXISTS threads(
     id SERIAL PRIMARY KEY,
     author CITEXT NOT NULL,
     created TIMESTAMP WITH TIME ZONE DEFAULT now(),
@@ -65,13 +45,13 @@ CREATE UNLOGGED TABLE threads(
     FOREIGN KEY (author) REFERENCES users (nickname) ON DELETE CASCADE
 );
 
-CREATE INDEX index_threads_forum_created ON threads (forum, created);
-CREATE INDEX index_threads_created ON threads (created);
-CREATE INDEX index_threads_slug_hash ON threads USING HASH (slug);
-CREATE INDEX index_threads_id_hash ON threads USING HASH (id);
+CREATE INDEX IF NOT EXISTS index_threads_forum_created ON threads (forum, created);
+CREATE INDEX IF NOT EXISTS index_threads_created ON threads (created);
+CREATE INDEX IF NOT EXISTS index_threads_slug_hash ON threads USING HASH (slug);
+CREATE INDEX IF NOT EXISTS index_threads_id_hash ON threads USING HASH (id);
 
 
-CREATE UNLOGGED TABLE posts(
+CREATE UNLOGGED TABLE IF NOT EXISTS posts(
     id BIGSERIAL PRIMARY KEY,
     author CITEXT NOT NULL,
     created TIMESTAMP WITH TIME ZONE,
@@ -83,13 +63,13 @@ CREATE UNLOGGED TABLE posts(
     path BIGINT[]
 );
 
-CREATE INDEX index_posts_id on posts (id);
-CREATE INDEX index_posts_thread_id on posts (thread, id);
-CREATE INDEX index_posts_thread_parent_path on posts (thread, parent, path);
-CREATE INDEX index_posts_path1_path on posts ((path[1]), path);
+CREATE INDEX IF NOT EXISTS index_posts_id on posts (id);
+CREATE INDEX IF NOT EXISTS index_posts_thread_id on posts (thread, id);
+CREATE INDEX IF NOT EXISTS index_posts_thread_parent_path on posts (thread, parent, path);
+CREATE INDEX IF NOT EXISTS index_posts_path1_path on posts ((path[1]), path);
 
 
-CREATE UNLOGGED TABLE thread_vote(
+CREATE UNLOGGED TABLE IF NOT EXISTS thread_vote(
     thread_id INT NOT NULL,
     vote INT NOT NULL,
     nickname CITEXT NOT NULL,
@@ -99,10 +79,10 @@ CREATE UNLOGGED TABLE thread_vote(
     UNIQUE (thread_id, nickname)
 );
 
-CREATE UNIQUE INDEX index_votes_user_thread ON thread_vote (thread_id, nickname);
+CREATE UNIQUE INDEX IF NOT EXISTS index_votes_user_thread ON thread_vote (thread_id, nickname);
 
 
-CREATE UNLOGGED TABLE forum_user(
+CREATE UNLOGGED TABLE IF NOT EXISTS forum_user(
     forum_slug CITEXT NOT NULL,
     nickname CITEXT NOT NULL,
 
@@ -110,8 +90,8 @@ CREATE UNLOGGED TABLE forum_user(
     FOREIGN KEY (nickname) REFERENCES users (nickname)
 );
 
-CREATE INDEX index_forum_user ON forum_user (forum_slug, nickname);
-CREATE INDEX index_forum_user_nickname ON forum_user (nickname);
+CREATE INDEX IF NOT EXISTS index_forum_user ON forum_user (forum_slug, nickname);
+CREATE INDEX IF NOT EXISTS index_forum_user_nickname ON forum_user (nickname);
 cluster forum_user USING index_forum_user;
 
 
@@ -130,6 +110,7 @@ BEGIN
 END;
 $insert_thread_votes$ language plpgsql;
 
+DROP TRIGGER IF EXISTS insert_thread_votes ON thread_vote;
 CREATE TRIGGER insert_thread_votes
     BEFORE INSERT
     ON thread_vote
@@ -154,6 +135,7 @@ BEGIN
 END;
 $update_thread_votes$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS update_thread_votes ON thread_vote;
 CREATE TRIGGER update_thread_votes
     BEFORE UPDATE
     ON thread_vote
@@ -171,6 +153,7 @@ BEGIN
 END;
 $set_post_path$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS set_post_path ON posts;
 CREATE TRIGGER set_post_path
     BEFORE INSERT
     ON posts
@@ -187,6 +170,7 @@ BEGIN
 END;
 $update_forum_threads$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS update_forum_threads ON threads;
 CREATE TRIGGER update_forum_threads
     BEFORE INSERT
     ON threads
@@ -205,12 +189,14 @@ BEGIN
 END;
 $add_forum_user$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS add_forum_user_new_thread ON threads;
 CREATE TRIGGER add_forum_user_new_thread
     AFTER INSERT
     ON threads
     FOR EACH ROW
 EXECUTE PROCEDURE add_forum_user();
 
+DROP TRIGGER IF EXISTS add_forum_user_new_post ON posts;
 CREATE TRIGGER add_forum_user_new_post
     AFTER INSERT
     ON posts
@@ -0,0 +1,25 @@
+-- Nicknames can now change (anonymization), so every reference follows
+-- the users row. Deleting a user no longer cascades into whole discussions:
+-- the repository removes their content explicitly and fixes the counters.
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_user_nickname_fkey;
+ALTER TABLE forums ADD CONSTRAINT forums_user_nickname_fkey
+    FOREIGN KEY (user_nickname) REFERENCES users (nickname) ON UPDATE CASCADE;
+
+ALTER TABLE threads DROP CONSTRAINT IF EXISTS threads_author_fkey;
+ALTER TABLE threads ADD CONSTRAINT threads_author_fkey
+    FOREIGN KEY (author) REFERENCES users (nickname) ON UPDATE CASCADE;
+
+ALTER TABLE thread_vote DROP CONSTRAINT IF EXISTS thread_vote_nickname_fkey;
+ALTER TABLE thread_vote ADD CONSTRAINT thread_vote_nickname_fkey
+    FOREIGN KEY (nickname) REFERENCES users (nickname) ON UPDATE CASCADE;
+
+ALTER TABLE thread_vote DROP CONSTRAINT IF EXISTS thread_vote_thread_id_fkey;
+ALTER TABLE thread_vote ADD CONSTRAINT thread_vote_thread_id_fkey
+    FOREIGN KEY (thread_id) REFERENCES threads (id) ON DELETE CASCADE;
+
+ALTER TABLE forum_user DROP CONSTRAINT IF EXISTS forum_user_nickname_fkey;
+ALTER TABLE forum_user ADD CONSTRAINT forum_user_nickname_fkey
+    FOREIGN KEY (nickname) REFERENCES users (nickname) ON UPDATE CASCADE;
+
+CREATE INDEX IF NOT EXISTS index_posts_author ON posts (author);
+CREATE INDEX IF NOT EXISTS index_threads_author ON threads (author);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS sessions;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
+
+ALTER TABLE forum_user SET UNLOGGED;
+ALTER TABLE thread_vote SET UNLOGGED;
+ALTER TABLE posts SET UNLOGGED;
+ALTER TABLE threads SET UNLOGGED;
+ALTER TABLE forums SET UNLOGGED;
+ALTER TABLE users SET UNLOGGED;
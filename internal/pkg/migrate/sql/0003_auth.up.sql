@@ -0,0 +1,25 @@
+-- The tables of 0001 are unlogged like those of the former init.sql, which tables
+-- that survive a crash can't reference. Referenced tables go first.
+ALTER TABLE users SET LOGGED;
+ALTER TABLE forums SET LOGGED;
+ALTER TABLE threads SET LOGGED;
+ALTER TABLE posts SET LOGGED;
+ALTER TABLE thread_vote SET LOGGED;
+ALTER TABLE forum_user SET LOGGED;
+
+-- Passwords are optional so that accounts created before authentication
+-- existed keep working in compatibility mode until they set one.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT;
+
+-- Only a SHA-256 of each token is stored, a leaked table can't be replayed.
+CREATE TABLE IF NOT EXISTS sessions(
+    token_hash BYTEA PRIMARY KEY,
+    user_id INT NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_sessions_user ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS index_sessions_expires ON sessions (expires);
@@ -0,0 +1,11 @@
+-- Former nicknames, so that old profile links keep resolving for a while after a rename.
+-- A name is recorded once: if it is given up again, the newest owner wins.
+CREATE TABLE IF NOT EXISTS nickname_history(
+    old_nickname CITEXT PRIMARY KEY,
+    user_id INT NOT NULL,
+    renamed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_nickname_history_user ON nickname_history (user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS index_users_nickname;
+DROP INDEX IF EXISTS index_users_fullname_trgm;
+DROP INDEX IF EXISTS index_users_nickname_trgm;
@@ -0,0 +1,7 @@
+-- Trigram indexes serve both prefix (LIKE 'abc%') and fuzzy (%) user search.
+-- pg_trgm only indexes text, so the citext columns are matched through lower(::text).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS index_users_nickname_trgm ON users USING GIN (lower(nickname::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS index_users_fullname_trgm ON users USING GIN (lower(fullname::text) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS index_users_nickname ON users (nickname);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS index_votes_nickname_thread;
+DROP INDEX IF EXISTS index_threads_author_created;
+DROP INDEX IF EXISTS index_posts_author_id;
+
+CREATE INDEX IF NOT EXISTS index_posts_author ON posts (author);
+CREATE INDEX IF NOT EXISTS index_threads_author ON threads (author);
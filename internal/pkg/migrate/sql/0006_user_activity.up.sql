@@ -0,0 +1,8 @@
+-- Per-user listings page through a user's threads by date and through
+-- posts and votes by id, so the author indexes carry the sort key too.
+DROP INDEX IF EXISTS index_posts_author;
+DROP INDEX IF EXISTS index_threads_author;
+
+CREATE INDEX IF NOT EXISTS index_posts_author_id ON posts (author, id);
+CREATE INDEX IF NOT EXISTS index_threads_author_created ON threads (author, created);
+CREATE INDEX IF NOT EXISTS index_votes_nickname_thread ON thread_vote (nickname, thread_id);
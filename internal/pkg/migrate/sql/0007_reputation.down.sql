@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS remove_thread_reputation ON threads;
+DROP FUNCTION IF EXISTS remove_thread_reputation();
+DROP TRIGGER IF EXISTS update_author_reputation ON thread_vote;
+DROP FUNCTION IF EXISTS update_author_reputation();
+
+ALTER TABLE users DROP COLUMN IF EXISTS reputation;
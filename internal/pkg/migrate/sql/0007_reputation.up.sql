@@ -0,0 +1,59 @@
+-- A user's reputation is the sum of the votes on their threads. The triggers keep it
+-- in step with thread_vote; "user reputation" rebuilds it if it ever drifts.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reputation INT NOT NULL DEFAULT 0;
+
+UPDATE users AS u SET reputation = COALESCE((
+    SELECT sum(v.vote) FROM threads AS t
+    JOIN thread_vote AS v ON v.thread_id = t.id
+    WHERE t.author = u.nickname
+), 0);
+
+
+CREATE OR REPLACE FUNCTION update_author_reputation()
+    RETURNS TRIGGER AS
+$update_author_reputation$
+BEGIN
+    IF tg_op = 'UPDATE' AND new.vote = old.vote AND new.thread_id = old.thread_id THEN
+        RETURN NULL;
+    END IF;
+
+    -- Votes removed together with their thread find no author here,
+    -- the thread's own trigger has already taken its votes back.
+    IF tg_op IN ('UPDATE', 'DELETE') THEN
+        UPDATE users SET reputation = reputation - old.vote
+        WHERE nickname = (SELECT author FROM threads WHERE id = old.thread_id);
+    END IF;
+
+    IF tg_op IN ('INSERT', 'UPDATE') THEN
+        UPDATE users SET reputation = reputation + new.vote
+        WHERE nickname = (SELECT author FROM threads WHERE id = new.thread_id);
+    END IF;
+
+    RETURN NULL;
+END;
+$update_author_reputation$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_author_reputation ON thread_vote;
+CREATE TRIGGER update_author_reputation
+    AFTER INSERT OR UPDATE OR DELETE
+    ON thread_vote
+    FOR EACH ROW
+EXECUTE PROCEDURE update_author_reputation();
+
+
+CREATE OR REPLACE FUNCTION remove_thread_reputation()
+    RETURNS TRIGGER AS
+$remove_thread_reputation$
+BEGIN
+    UPDATE users SET reputation = reputation - old.votes
+    WHERE nickname = old.author;
+    RETURN NULL;
+END;
+$remove_thread_reputation$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS remove_thread_reputation ON threads;
+CREATE TRIGGER remove_thread_reputation
+    AFTER DELETE
+    ON threads
+    FOR EACH ROW
+EXECUTE PROCEDURE remove_thread_reputation();
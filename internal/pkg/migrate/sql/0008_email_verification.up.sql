@@ -0,0 +1,19 @@
+-- Accounts created before verification existed are trusted as they are,
+-- new ones start unverified.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified BOOLEAN;
+UPDATE users SET verified = true WHERE verified IS NULL;
+ALTER TABLE users ALTER COLUMN verified SET DEFAULT false;
+ALTER TABLE users ALTER COLUMN verified SET NOT NULL;
+
+-- A pending confirmation of email for the user. It is the current address
+-- after registration and the requested one after an email change.
+CREATE TABLE IF NOT EXISTS email_verifications(
+    token_hash BYTEA PRIMARY KEY,
+    user_id INT NOT NULL,
+    email CITEXT NOT NULL,
+    expires TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_email_verifications_user ON email_verifications (user_id);
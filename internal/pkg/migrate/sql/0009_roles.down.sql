@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS forum_moderators;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
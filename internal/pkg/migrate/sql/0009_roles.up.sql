@@ -0,0 +1,17 @@
+-- Global roles live on users; moderation is per forum. The forum owner
+-- (forums.user_nickname) always moderates it and is not listed here.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('member', 'admin'));
+
+CREATE TABLE IF NOT EXISTS forum_moderators(
+    forum_slug CITEXT NOT NULL,
+    user_id INT NOT NULL,
+    granted TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (forum_slug, user_id),
+    FOREIGN KEY (forum_slug) REFERENCES forums (slug) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_forum_moderators_user ON forum_moderators (user_id);
@@ -0,0 +1,14 @@
+-- Who each user ignores. Listings requested with ?viewer= hide or collapse
+-- the content of ignored authors.
+CREATE TABLE IF NOT EXISTS user_ignore(
+    user_id INT NOT NULL,
+    ignored_id INT NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (user_id, ignored_id),
+    CHECK (user_id <> ignored_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (ignored_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_user_ignore_ignored ON user_ignore (ignored_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS index_forums_parent;
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_parent_check;
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_parent_fkey;
+ALTER TABLE forums DROP COLUMN IF EXISTS parent;
@@ -0,0 +1,10 @@
+-- Forums may sit below a parent forum. The application keeps the hierarchy
+-- acyclic and refuses to delete forums that still have subforums.
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS parent CITEXT;
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_parent_fkey;
+ALTER TABLE forums ADD CONSTRAINT forums_parent_fkey
+    FOREIGN KEY (parent) REFERENCES forums (slug) ON UPDATE CASCADE;
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_parent_check;
+ALTER TABLE forums ADD CONSTRAINT forums_parent_check CHECK (parent <> slug);
+
+CREATE INDEX IF NOT EXISTS index_forums_parent ON forums (parent, slug);
@@ -0,0 +1,22 @@
+CREATE OR REPLACE FUNCTION set_post_path()
+    RETURNS TRIGGER AS
+$set_post_path$
+BEGIN
+    new.path = (SELECT path FROM posts WHERE id = new.parent) || new.id;
+    UPDATE forums SET post_count = post_count + 1 WHERE slug = new.forum;
+    RETURN new;
+END;
+$set_post_path$ LANGUAGE plpgsql;
+
+
+CREATE OR REPLACE FUNCTION update_forum_threads()
+    RETURNS TRIGGER AS
+$update_forum_threads$
+BEGIN
+    UPDATE forums SET thread_count = thread_count + 1 WHERE slug = new.forum;
+    RETURN new;
+END;
+$update_forum_threads$ LANGUAGE plpgsql;
+
+ALTER TABLE forums DROP COLUMN IF EXISTS last_activity;
+ALTER TABLE forums DROP COLUMN IF EXISTS created;
@@ -0,0 +1,42 @@
+-- When each forum was created and last saw a new thread or post, for GET /api/forums.
+-- Forums that existed before count as created with their first thread.
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS created TIMESTAMP WITH TIME ZONE;
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS last_activity TIMESTAMP WITH TIME ZONE;
+
+UPDATE forums AS f
+SET created = COALESCE(t.first, now()),
+    last_activity = COALESCE(GREATEST(t.last, p.last), t.first, now())
+FROM forums AS o
+LEFT JOIN (SELECT forum, min(created) AS first, max(created) AS last FROM threads GROUP BY forum) AS t ON t.forum = o.slug
+LEFT JOIN (SELECT forum, max(created) AS last FROM posts GROUP BY forum) AS p ON p.forum = o.slug
+WHERE f.id = o.id;
+
+ALTER TABLE forums ALTER COLUMN created SET DEFAULT now();
+ALTER TABLE forums ALTER COLUMN created SET NOT NULL;
+ALTER TABLE forums ALTER COLUMN last_activity SET DEFAULT now();
+ALTER TABLE forums ALTER COLUMN last_activity SET NOT NULL;
+
+
+CREATE OR REPLACE FUNCTION set_post_path()
+    RETURNS TRIGGER AS
+$set_post_path$
+BEGIN
+    new.path = (SELECT path FROM posts WHERE id = new.parent) || new.id;
+    UPDATE forums SET post_count = post_count + 1,
+    last_activity = GREATEST(last_activity, COALESCE(new.created, now()))
+    WHERE slug = new.forum;
+    RETURN new;
+END;
+$set_post_path$ LANGUAGE plpgsql;
+
+
+CREATE OR REPLACE FUNCTION update_forum_threads()
+    RETURNS TRIGGER AS
+$update_forum_threads$
+BEGIN
+    UPDATE forums SET thread_count = thread_count + 1,
+    last_activity = GREATEST(last_activity, COALESCE(new.created, now()))
+    WHERE slug = new.forum;
+    RETURN new;
+END;
+$update_forum_threads$ LANGUAGE plpgsql;
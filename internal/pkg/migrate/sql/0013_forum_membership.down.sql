@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS forum_members;
+
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_visibility_check;
+ALTER TABLE forums DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,22 @@
+-- Who may see and write to a forum. Restricted forums are read by everyone but
+-- written by members only; private forums are hidden from non-members.
+ALTER TABLE forums ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE forums DROP CONSTRAINT IF EXISTS forums_visibility_check;
+ALTER TABLE forums ADD CONSTRAINT forums_visibility_check CHECK (visibility IN ('public', 'restricted', 'private'));
+
+-- Explicit membership, unlike forum_user, which only records who has posted.
+-- A moderator's invitation and the user's request both have to be there
+-- before a user becomes a member.
+CREATE TABLE IF NOT EXISTS forum_members(
+    forum_slug CITEXT NOT NULL,
+    user_id INT NOT NULL,
+    status TEXT NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (forum_slug, user_id),
+    CHECK (status IN ('invited', 'requested', 'member')),
+    FOREIGN KEY (forum_slug) REFERENCES forums (slug) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS index_forum_members_user ON forum_members (user_id);
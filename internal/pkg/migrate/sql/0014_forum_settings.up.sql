@@ -0,0 +1,14 @@
+-- Rules the owner sets for writing to a forum. Forums without a row here
+-- use the defaults of forum.DefaultSettings; zero means no limit.
+CREATE TABLE IF NOT EXISTS forum_settings(
+    forum_slug CITEXT NOT NULL PRIMARY KEY,
+    max_post_length INT NOT NULL CHECK (max_post_length >= 0),
+    post_interval INT NOT NULL CHECK (post_interval >= 0),
+    allow_votes BOOLEAN NOT NULL,
+    owner_threads BOOLEAN NOT NULL,
+    nested_replies BOOLEAN NOT NULL,
+    max_reply_depth INT NOT NULL CHECK (max_reply_depth >= 0),
+    updated TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (forum_slug) REFERENCES forums (slug) ON UPDATE CASCADE ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/go-openapi/strfmt"
+
+//easyjson:json
+type Credentials struct {
+	Nickname string `json:"nickname"`
+	Password string `json:"password"`
+}
+
+//easyjson:json
+type Session struct {
+	Token    string          `json:"token"`
+	Nickname string          `json:"nickname"`
+	Expires  strfmt.DateTime `json:"expires"`
+}
@@ -4,11 +4,48 @@ import "github.com/go-openapi/strfmt"
 
 //easyjson:json
 type Forum struct {
+	Slug    string  `json:"slug"`
+	Title   string  `json:"title"`
+	User    string  `json:"user"`
+	Parent  *string `json:"parent,omitempty"`
+	Threads int     `json:"threads"`
+	Posts   int     `json:"posts"`
+	// Visibility is public, restricted or private.
+	Visibility string `json:"visibility,omitempty"`
+	// Created and Activity, the time of the latest thread or post, are filled in when read back.
+	Created  *strfmt.DateTime `json:"created,omitempty"`
+	Activity *strfmt.DateTime `json:"activity,omitempty"`
+	// Breadcrumb lists the ancestors from the top-level forum down to the parent.
+	Breadcrumb []ForumLink `json:"breadcrumb,omitempty"`
+}
+
+//easyjson:json
+type ForumLink struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+}
+
+//easyjson:json
+type ForumSettings struct {
+	// MaxPostLength counts characters of a post or an opening message, 0 for no limit.
+	MaxPostLength int `json:"max_post_length"`
+	// PostInterval is how many seconds an author must wait between posts, 0 for none.
+	PostInterval int  `json:"post_interval"`
+	AllowVotes   bool `json:"allow_votes"`
+	OwnerThreads bool `json:"owner_threads"`
+	// NestedReplies allows replies to posts; without it every post is top-level.
+	NestedReplies bool `json:"nested_replies"`
+	// MaxReplyDepth limits how many posts a reply may be below, 0 for no limit.
+	MaxReplyDepth int `json:"max_reply_depth"`
+}
+
+//easyjson:json
+type ForumDeletion struct {
 	Slug    string `json:"slug"`
-	Title   string `json:"title"`
-	User    string `json:"user"`
-	Threads int    `json:"threads"`
-	Posts   int    `json:"posts"`
+	Threads int64  `json:"threads"`
+	Posts   int64  `json:"posts"`
+	Votes   int64  `json:"votes"`
+	Users   int64  `json:"users"`
 }
 
 //easyjson:json
@@ -33,6 +70,8 @@ type Post struct {
 	Thread   int             `json:"thread"`
 	Created  strfmt.DateTime `json:"created,omitempty"`
 	IsEdited bool            `json:"isEdited"`
+	// Ignored marks a post whose author the viewer ignores. Its message is left out.
+	Ignored bool `json:"ignored,omitempty"`
 }
 
 //easyjson:json
@@ -50,3 +89,23 @@ type ServiceInfo struct {
 	Thread int `json:"thread"`
 	User   int `json:"user"`
 }
+
+//easyjson:json
+type ThreadVote struct {
+	Voice  int    `json:"voice"`
+	Thread Thread `json:"thread"`
+}
+
+//easyjson:json
+type Moderator struct {
+	Nickname string `json:"nickname"`
+	Owner    bool   `json:"owner"`
+}
+
+//easyjson:json
+type Member struct {
+	Nickname string `json:"nickname"`
+	// Status is invited or requested until both sides have agreed, then member.
+	Status string          `json:"status"`
+	Since  strfmt.DateTime `json:"since"`
+}
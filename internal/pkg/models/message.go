@@ -3,4 +3,5 @@ package models
 //easyjson:json
 type Message struct {
 	Text string `json:"message"`
+	Code string `json:"code,omitempty"`
 }
@@ -4,6 +4,7 @@ package models
 
 import (
 	json "encoding/json"
+	strfmt "github.com/go-openapi/strfmt"
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
@@ -109,7 +110,73 @@ func (v *Vote) UnmarshalJSON(data []byte) error {
 func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(in *jlexer.Lexer, out *User) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(in *jlexer.Lexer, out *Verification) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "token":
+			out.Token = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(out *jwriter.Writer, in Verification) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"token\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Token))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Verification) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Verification) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Verification) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Verification) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(in *jlexer.Lexer, out *User) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -160,6 +227,20 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(in *jlexer.
 				}
 				*out.About = string(in.String())
 			}
+		case "reputation":
+			out.Reputation = int(in.Int())
+		case "verified":
+			out.Verified = bool(in.Bool())
+		case "pending_email":
+			if in.IsNull() {
+				in.Skip()
+				out.PendingEmail = nil
+			} else {
+				if out.PendingEmail == nil {
+					out.PendingEmail = new(string)
+				}
+				*out.PendingEmail = string(in.String())
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -170,7 +251,7 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(out *jwriter.Writer, in User) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(out *jwriter.Writer, in User) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -199,33 +280,121 @@ func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(out *jwrite
 		out.RawString(prefix)
 		out.String(string(*in.About))
 	}
+	{
+		const prefix string = ",\"reputation\":"
+		out.RawString(prefix)
+		out.Int(int(in.Reputation))
+	}
+	{
+		const prefix string = ",\"verified\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Verified))
+	}
+	if in.PendingEmail != nil {
+		const prefix string = ",\"pending_email\":"
+		out.RawString(prefix)
+		out.String(string(*in.PendingEmail))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v User) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v User) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels1(w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *User) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels1(l, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(in *jlexer.Lexer, out *ThreadVote) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "voice":
+			out.Voice = int(in.Int())
+		case "thread":
+			(out.Thread).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(out *jwriter.Writer, in ThreadVote) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Voice))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		(in.Thread).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ThreadVote) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(in *jlexer.Lexer, out *Thread) {
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ThreadVote) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ThreadVote) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ThreadVote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(in *jlexer.Lexer, out *Thread) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -280,7 +449,7 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(out *jwriter.Writer, in Thread) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(out *jwriter.Writer, in Thread) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -330,27 +499,27 @@ func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(out *jwrite
 // MarshalJSON supports json.Marshaler interface
 func (v Thread) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Thread) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels2(w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Thread) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Thread) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels2(l, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(in *jlexer.Lexer, out *ServiceInfo) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(in *jlexer.Lexer, out *Session) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -369,14 +538,14 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(in *jlexer.
 			continue
 		}
 		switch key {
-		case "forum":
-			out.Forum = int(in.Int())
-		case "post":
-			out.Post = int(in.Int())
-		case "thread":
-			out.Thread = int(in.Int())
-		case "user":
-			out.User = int(in.Int())
+		case "token":
+			out.Token = string(in.String())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "expires":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Expires).UnmarshalJSON(data))
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -387,57 +556,52 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(out *jwriter.Writer, in ServiceInfo) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(out *jwriter.Writer, in Session) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"forum\":"
+		const prefix string = ",\"token\":"
 		out.RawString(prefix[1:])
-		out.Int(int(in.Forum))
-	}
-	{
-		const prefix string = ",\"post\":"
-		out.RawString(prefix)
-		out.Int(int(in.Post))
+		out.String(string(in.Token))
 	}
 	{
-		const prefix string = ",\"thread\":"
+		const prefix string = ",\"nickname\":"
 		out.RawString(prefix)
-		out.Int(int(in.Thread))
+		out.String(string(in.Nickname))
 	}
 	{
-		const prefix string = ",\"user\":"
+		const prefix string = ",\"expires\":"
 		out.RawString(prefix)
-		out.Int(int(in.User))
+		out.Raw((in.Expires).MarshalJSON())
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ServiceInfo) MarshalJSON() ([]byte, error) {
+func (v Session) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ServiceInfo) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels3(w, v)
+func (v Session) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ServiceInfo) UnmarshalJSON(data []byte) error {
+func (v *Session) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ServiceInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels3(l, v)
+func (v *Session) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(in *jlexer.Lexer, out *PostInfo) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(in *jlexer.Lexer, out *ServiceInfo) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -456,38 +620,14 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(in *jlexer.
 			continue
 		}
 		switch key {
+		case "forum":
+			out.Forum = int(in.Int())
 		case "post":
-			(out.Post).UnmarshalEasyJSON(in)
-		case "author":
-			if in.IsNull() {
-				in.Skip()
-				out.Author = nil
-			} else {
-				if out.Author == nil {
-					out.Author = new(User)
-				}
-				(*out.Author).UnmarshalEasyJSON(in)
-			}
+			out.Post = int(in.Int())
 		case "thread":
-			if in.IsNull() {
-				in.Skip()
-				out.Thread = nil
-			} else {
-				if out.Thread == nil {
-					out.Thread = new(Thread)
-				}
-				(*out.Thread).UnmarshalEasyJSON(in)
-			}
-		case "forum":
-			if in.IsNull() {
-				in.Skip()
-				out.Forum = nil
-			} else {
-				if out.Forum == nil {
-					out.Forum = new(Forum)
-				}
-				(*out.Forum).UnmarshalEasyJSON(in)
-			}
+			out.Thread = int(in.Int())
+		case "user":
+			out.User = int(in.Int())
 		default:
 			in.SkipRecursive()
 		}
@@ -498,57 +638,234 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(out *jwriter.Writer, in PostInfo) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(out *jwriter.Writer, in ServiceInfo) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"post\":"
+		const prefix string = ",\"forum\":"
 		out.RawString(prefix[1:])
-		(in.Post).MarshalEasyJSON(out)
+		out.Int(int(in.Forum))
 	}
-	if in.Author != nil {
-		const prefix string = ",\"author\":"
+	{
+		const prefix string = ",\"post\":"
 		out.RawString(prefix)
-		(*in.Author).MarshalEasyJSON(out)
+		out.Int(int(in.Post))
 	}
-	if in.Thread != nil {
+	{
 		const prefix string = ",\"thread\":"
 		out.RawString(prefix)
-		(*in.Thread).MarshalEasyJSON(out)
+		out.Int(int(in.Thread))
 	}
-	if in.Forum != nil {
-		const prefix string = ",\"forum\":"
+	{
+		const prefix string = ",\"user\":"
 		out.RawString(prefix)
-		(*in.Forum).MarshalEasyJSON(out)
+		out.Int(int(in.User))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v PostInfo) MarshalJSON() ([]byte, error) {
+func (v ServiceInfo) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v PostInfo) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels4(w, v)
+func (v ServiceInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *PostInfo) UnmarshalJSON(data []byte) error {
+func (v *ServiceInfo) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *PostInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels4(l, v)
+func (v *ServiceInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(in *jlexer.Lexer, out *Rename) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(out *jwriter.Writer, in Rename) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Rename) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Rename) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Rename) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Rename) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels8(in *jlexer.Lexer, out *PostInfo) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "post":
+			(out.Post).UnmarshalEasyJSON(in)
+		case "author":
+			if in.IsNull() {
+				in.Skip()
+				out.Author = nil
+			} else {
+				if out.Author == nil {
+					out.Author = new(User)
+				}
+				(*out.Author).UnmarshalEasyJSON(in)
+			}
+		case "thread":
+			if in.IsNull() {
+				in.Skip()
+				out.Thread = nil
+			} else {
+				if out.Thread == nil {
+					out.Thread = new(Thread)
+				}
+				(*out.Thread).UnmarshalEasyJSON(in)
+			}
+		case "forum":
+			if in.IsNull() {
+				in.Skip()
+				out.Forum = nil
+			} else {
+				if out.Forum == nil {
+					out.Forum = new(Forum)
+				}
+				(*out.Forum).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels8(out *jwriter.Writer, in PostInfo) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"post\":"
+		out.RawString(prefix[1:])
+		(in.Post).MarshalEasyJSON(out)
+	}
+	if in.Author != nil {
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		(*in.Author).MarshalEasyJSON(out)
+	}
+	if in.Thread != nil {
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		(*in.Thread).MarshalEasyJSON(out)
+	}
+	if in.Forum != nil {
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		(*in.Forum).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PostInfo) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels8(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PostInfo) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels8(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PostInfo) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels8(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PostInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels8(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(in *jlexer.Lexer, out *Post) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels9(in *jlexer.Lexer, out *Post) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -585,6 +902,8 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(in *jlexer.
 			}
 		case "isEdited":
 			out.IsEdited = bool(in.Bool())
+		case "ignored":
+			out.Ignored = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -595,7 +914,7 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(out *jwriter.Writer, in Post) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels9(out *jwriter.Writer, in Post) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -639,33 +958,38 @@ func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(out *jwrite
 		out.RawString(prefix)
 		out.Bool(bool(in.IsEdited))
 	}
+	if in.Ignored {
+		const prefix string = ",\"ignored\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Ignored))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Post) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels5(w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Post) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels5(l, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels9(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(in *jlexer.Lexer, out *Message) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels10(in *jlexer.Lexer, out *Moderator) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -684,8 +1008,10 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(in *jlexer.
 			continue
 		}
 		switch key {
-		case "message":
-			out.Text = string(in.String())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "owner":
+			out.Owner = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -696,42 +1022,47 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(out *jwriter.Writer, in Message) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels10(out *jwriter.Writer, in Moderator) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"message\":"
+		const prefix string = ",\"nickname\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Text))
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"owner\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Owner))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Message) MarshalJSON() ([]byte, error) {
+func (v Moderator) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels10(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Message) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels6(w, v)
+func (v Moderator) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels10(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Message) UnmarshalJSON(data []byte) error {
+func (v *Moderator) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels10(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Message) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels6(l, v)
+func (v *Moderator) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels10(l, v)
 }
-func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(in *jlexer.Lexer, out *Forum) {
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels11(in *jlexer.Lexer, out *Message) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -750,16 +1081,10 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(in *jlexer.
 			continue
 		}
 		switch key {
-		case "slug":
-			out.Slug = string(in.String())
-		case "title":
-			out.Title = string(in.String())
-		case "user":
-			out.User = string(in.String())
-		case "threads":
-			out.Threads = int(in.Int())
-		case "posts":
-			out.Posts = int(in.Int())
+		case "message":
+			out.Text = string(in.String())
+		case "code":
+			out.Code = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -770,58 +1095,1284 @@ func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(in *jlexer.
 		in.Consumed()
 	}
 }
-func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(out *jwriter.Writer, in Forum) {
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels11(out *jwriter.Writer, in Message) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"slug\":"
+		const prefix string = ",\"message\":"
 		out.RawString(prefix[1:])
-		out.String(string(in.Slug))
-	}
-	{
-		const prefix string = ",\"title\":"
-		out.RawString(prefix)
-		out.String(string(in.Title))
-	}
-	{
-		const prefix string = ",\"user\":"
-		out.RawString(prefix)
-		out.String(string(in.User))
-	}
-	{
-		const prefix string = ",\"threads\":"
-		out.RawString(prefix)
-		out.Int(int(in.Threads))
+		out.String(string(in.Text))
 	}
-	{
-		const prefix string = ",\"posts\":"
+	if in.Code != "" {
+		const prefix string = ",\"code\":"
 		out.RawString(prefix)
-		out.Int(int(in.Posts))
+		out.String(string(in.Code))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Forum) MarshalJSON() ([]byte, error) {
+func (v Message) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(&w, v)
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels11(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Forum) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels7(w, v)
+func (v Message) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels11(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Forum) UnmarshalJSON(data []byte) error {
+func (v *Message) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(&r, v)
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels11(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Forum) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels7(l, v)
+func (v *Message) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels11(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels12(in *jlexer.Lexer, out *Member) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "since":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Since).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels12(out *jwriter.Writer, in Member) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"since\":"
+		out.RawString(prefix)
+		out.Raw((in.Since).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Member) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels12(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Member) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels12(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Member) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels12(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Member) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels12(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels13(in *jlexer.Lexer, out *ImportedUsers) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ImportedUsers, 0, 0)
+			} else {
+				*out = ImportedUsers{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 ImportedUser
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels13(out *jwriter.Writer, in ImportedUsers) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportedUsers) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels13(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportedUsers) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels13(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportedUsers) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels13(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportedUsers) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels13(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels14(in *jlexer.Lexer, out *ImportedUser) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			if in.IsNull() {
+				in.Skip()
+				out.Fullname = nil
+			} else {
+				if out.Fullname == nil {
+					out.Fullname = new(string)
+				}
+				*out.Fullname = string(in.String())
+			}
+		case "email":
+			if in.IsNull() {
+				in.Skip()
+				out.Email = nil
+			} else {
+				if out.Email == nil {
+					out.Email = new(string)
+				}
+				*out.Email = string(in.String())
+			}
+		case "about":
+			if in.IsNull() {
+				in.Skip()
+				out.About = nil
+			} else {
+				if out.About == nil {
+					out.About = new(string)
+				}
+				*out.About = string(in.String())
+			}
+		case "password":
+			out.Password = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels14(out *jwriter.Writer, in ImportedUser) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	if in.Fullname != nil {
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix)
+		out.String(string(*in.Fullname))
+	}
+	if in.Email != nil {
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		out.String(string(*in.Email))
+	}
+	if in.About != nil {
+		const prefix string = ",\"about\":"
+		out.RawString(prefix)
+		out.String(string(*in.About))
+	}
+	if in.Password != "" {
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportedUser) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels14(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportedUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels14(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportedUser) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels14(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportedUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels14(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels15(in *jlexer.Lexer, out *ImportResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "index":
+			out.Index = int(in.Int())
+		case "line":
+			out.Line = int(in.Int())
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "conflicts":
+			if in.IsNull() {
+				in.Skip()
+				out.Conflicts = nil
+			} else {
+				in.Delim('[')
+				if out.Conflicts == nil {
+					if !in.IsDelim(']') {
+						out.Conflicts = make([]User, 0, 0)
+					} else {
+						out.Conflicts = []User{}
+					}
+				} else {
+					out.Conflicts = (out.Conflicts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 User
+					(v4).UnmarshalEasyJSON(in)
+					out.Conflicts = append(out.Conflicts, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "code":
+			out.Code = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels15(out *jwriter.Writer, in ImportResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"index\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Index))
+	}
+	if in.Line != 0 {
+		const prefix string = ",\"line\":"
+		out.RawString(prefix)
+		out.Int(int(in.Line))
+	}
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if len(in.Conflicts) != 0 {
+		const prefix string = ",\"conflicts\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Conflicts {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				(v6).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Code != "" {
+		const prefix string = ",\"code\":"
+		out.RawString(prefix)
+		out.String(string(in.Code))
+	}
+	if in.Message != "" {
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels15(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels15(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels15(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels15(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels16(in *jlexer.Lexer, out *ImportReport) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "created":
+			out.Created = int(in.Int())
+		case "conflicted":
+			out.Conflicted = int(in.Int())
+		case "invalid":
+			out.Invalid = int(in.Int())
+		case "results":
+			if in.IsNull() {
+				in.Skip()
+				out.Results = nil
+			} else {
+				in.Delim('[')
+				if out.Results == nil {
+					if !in.IsDelim(']') {
+						out.Results = make([]ImportResult, 0, 0)
+					} else {
+						out.Results = []ImportResult{}
+					}
+				} else {
+					out.Results = (out.Results)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 ImportResult
+					(v7).UnmarshalEasyJSON(in)
+					out.Results = append(out.Results, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels16(out *jwriter.Writer, in ImportReport) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Created))
+	}
+	{
+		const prefix string = ",\"conflicted\":"
+		out.RawString(prefix)
+		out.Int(int(in.Conflicted))
+	}
+	{
+		const prefix string = ",\"invalid\":"
+		out.RawString(prefix)
+		out.Int(int(in.Invalid))
+	}
+	{
+		const prefix string = ",\"results\":"
+		out.RawString(prefix)
+		if in.Results == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v8, v9 := range in.Results {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				(v9).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ImportReport) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels16(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ImportReport) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels16(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ImportReport) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels16(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ImportReport) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels16(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels17(in *jlexer.Lexer, out *HealthReport) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "checks":
+			if in.IsNull() {
+				in.Skip()
+				out.Checks = nil
+			} else {
+				in.Delim('[')
+				if out.Checks == nil {
+					if !in.IsDelim(']') {
+						out.Checks = make([]HealthCheck, 0, 1)
+					} else {
+						out.Checks = []HealthCheck{}
+					}
+				} else {
+					out.Checks = (out.Checks)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v10 HealthCheck
+					(v10).UnmarshalEasyJSON(in)
+					out.Checks = append(out.Checks, v10)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels17(out *jwriter.Writer, in HealthReport) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	if len(in.Checks) != 0 {
+		const prefix string = ",\"checks\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v11, v12 := range in.Checks {
+				if v11 > 0 {
+					out.RawByte(',')
+				}
+				(v12).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HealthReport) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels17(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HealthReport) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels17(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HealthReport) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels17(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HealthReport) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels17(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels18(in *jlexer.Lexer, out *HealthCheck) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		case "duration":
+			out.Duration = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels18(out *jwriter.Writer, in HealthCheck) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	{
+		const prefix string = ",\"duration\":"
+		out.RawString(prefix)
+		out.String(string(in.Duration))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HealthCheck) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels18(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HealthCheck) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels18(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HealthCheck) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels18(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HealthCheck) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels18(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels19(in *jlexer.Lexer, out *ForumSettings) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "max_post_length":
+			out.MaxPostLength = int(in.Int())
+		case "post_interval":
+			out.PostInterval = int(in.Int())
+		case "allow_votes":
+			out.AllowVotes = bool(in.Bool())
+		case "owner_threads":
+			out.OwnerThreads = bool(in.Bool())
+		case "nested_replies":
+			out.NestedReplies = bool(in.Bool())
+		case "max_reply_depth":
+			out.MaxReplyDepth = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels19(out *jwriter.Writer, in ForumSettings) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"max_post_length\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.MaxPostLength))
+	}
+	{
+		const prefix string = ",\"post_interval\":"
+		out.RawString(prefix)
+		out.Int(int(in.PostInterval))
+	}
+	{
+		const prefix string = ",\"allow_votes\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.AllowVotes))
+	}
+	{
+		const prefix string = ",\"owner_threads\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.OwnerThreads))
+	}
+	{
+		const prefix string = ",\"nested_replies\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.NestedReplies))
+	}
+	{
+		const prefix string = ",\"max_reply_depth\":"
+		out.RawString(prefix)
+		out.Int(int(in.MaxReplyDepth))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumSettings) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels19(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumSettings) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels19(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumSettings) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels19(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumSettings) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels19(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels20(in *jlexer.Lexer, out *ForumLink) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels20(out *jwriter.Writer, in ForumLink) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumLink) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels20(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumLink) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels20(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumLink) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels20(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumLink) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels20(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels21(in *jlexer.Lexer, out *ForumDeletion) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "slug":
+			out.Slug = string(in.String())
+		case "threads":
+			out.Threads = int64(in.Int64())
+		case "posts":
+			out.Posts = int64(in.Int64())
+		case "votes":
+			out.Votes = int64(in.Int64())
+		case "users":
+			out.Users = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels21(out *jwriter.Writer, in ForumDeletion) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Posts))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Votes))
+	}
+	{
+		const prefix string = ",\"users\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Users))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumDeletion) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels21(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumDeletion) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels21(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumDeletion) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels21(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumDeletion) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels21(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels22(in *jlexer.Lexer, out *Forum) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "user":
+			out.User = string(in.String())
+		case "parent":
+			if in.IsNull() {
+				in.Skip()
+				out.Parent = nil
+			} else {
+				if out.Parent == nil {
+					out.Parent = new(string)
+				}
+				*out.Parent = string(in.String())
+			}
+		case "threads":
+			out.Threads = int(in.Int())
+		case "posts":
+			out.Posts = int(in.Int())
+		case "visibility":
+			out.Visibility = string(in.String())
+		case "created":
+			if in.IsNull() {
+				in.Skip()
+				out.Created = nil
+			} else {
+				if out.Created == nil {
+					out.Created = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.Created).UnmarshalJSON(data))
+				}
+			}
+		case "activity":
+			if in.IsNull() {
+				in.Skip()
+				out.Activity = nil
+			} else {
+				if out.Activity == nil {
+					out.Activity = new(strfmt.DateTime)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.Activity).UnmarshalJSON(data))
+				}
+			}
+		case "breadcrumb":
+			if in.IsNull() {
+				in.Skip()
+				out.Breadcrumb = nil
+			} else {
+				in.Delim('[')
+				if out.Breadcrumb == nil {
+					if !in.IsDelim(']') {
+						out.Breadcrumb = make([]ForumLink, 0, 2)
+					} else {
+						out.Breadcrumb = []ForumLink{}
+					}
+				} else {
+					out.Breadcrumb = (out.Breadcrumb)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v13 ForumLink
+					(v13).UnmarshalEasyJSON(in)
+					out.Breadcrumb = append(out.Breadcrumb, v13)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels22(out *jwriter.Writer, in Forum) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		out.String(string(in.User))
+	}
+	if in.Parent != nil {
+		const prefix string = ",\"parent\":"
+		out.RawString(prefix)
+		out.String(string(*in.Parent))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Posts))
+	}
+	if in.Visibility != "" {
+		const prefix string = ",\"visibility\":"
+		out.RawString(prefix)
+		out.String(string(in.Visibility))
+	}
+	if in.Created != nil {
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((*in.Created).MarshalJSON())
+	}
+	if in.Activity != nil {
+		const prefix string = ",\"activity\":"
+		out.RawString(prefix)
+		out.Raw((*in.Activity).MarshalJSON())
+	}
+	if len(in.Breadcrumb) != 0 {
+		const prefix string = ",\"breadcrumb\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v14, v15 := range in.Breadcrumb {
+				if v14 > 0 {
+					out.RawByte(',')
+				}
+				(v15).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Forum) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels22(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Forum) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels22(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Forum) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels22(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Forum) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels22(l, v)
+}
+func easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels23(in *jlexer.Lexer, out *Credentials) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "password":
+			out.Password = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels23(out *jwriter.Writer, in Credentials) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"password\":"
+		out.RawString(prefix)
+		out.String(string(in.Password))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Credentials) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels23(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Credentials) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD2b7633eEncodeGithubComAanufrievForumInternalPkgModels23(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Credentials) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels23(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Credentials) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD2b7633eDecodeGithubComAanufrievForumInternalPkgModels23(l, v)
 }
@@ -7,6 +7,49 @@ type User struct {
 	Fullname *string `json:"fullname,omitempty"`
 	Email    *string `json:"email,omitempty"`
 	About    *string `json:"about,omitempty"`
+	// Reputation is the sum of the votes on the user's threads. It is never read from requests.
+	Reputation int  `json:"reputation"`
+	Verified   bool `json:"verified"`
+	// PendingEmail is set in answers to an email change that awaits confirmation.
+	PendingEmail *string `json:"pending_email,omitempty"`
+
+	PasswordHash *string `json:"-"`
+}
+
+//easyjson:json
+type ImportedUser struct {
+	Nickname string  `json:"nickname"`
+	Fullname *string `json:"fullname,omitempty"`
+	Email    *string `json:"email,omitempty"`
+	About    *string `json:"about,omitempty"`
+	Password string  `json:"password,omitempty"`
+	// Line is where the entry starts in an NDJSON body, Malformed why it couldn't be read.
+	Line      int   `json:"-"`
+	Malformed error `json:"-"`
+}
+
+//easyjson:json
+type ImportedUsers []ImportedUser
+
+//easyjson:json
+type ImportResult struct {
+	Index    int    `json:"index"`
+	Line     int    `json:"line,omitempty"`
+	Nickname string `json:"nickname"`
+	Status   string `json:"status"`
+	// Conflicts are the existing users the entry clashes with, as creating it alone would report them.
+	Conflicts []User `json:"conflicts,omitempty"`
+	// Code and Message explain why an entry is invalid.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+//easyjson:json
+type ImportReport struct {
+	Created    int            `json:"created"`
+	Conflicted int            `json:"conflicted"`
+	Invalid    int            `json:"invalid"`
+	Results    []ImportResult `json:"results"`
 }
 
 //easyjson:json
@@ -17,3 +60,13 @@ type Vote struct {
 	ID       int    `json:"id"`
 	Slug     string `json:"slug"`
 }
+
+//easyjson:json
+type Verification struct {
+	Token string `json:"token"`
+}
+
+//easyjson:json
+type Rename struct {
+	Nickname string `json:"nickname"`
+}
@@ -0,0 +1,117 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/response"
+	"github.com/valyala/fasthttp"
+)
+
+type PermissionDelivery struct {
+	permissionUsecase permission.Usecase
+	timeout           time.Duration
+}
+
+func New(permissionUsecase permission.Usecase, timeout time.Duration) PermissionDelivery {
+	return PermissionDelivery{
+		permissionUsecase: permissionUsecase,
+		timeout:           timeout,
+	}
+}
+
+func (p PermissionDelivery) GetModerators(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+
+	moderators, err := p.permissionUsecase.GetModerators(reqCtx, slug)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, moderators)
+}
+
+func (p PermissionDelivery) GrantModerator(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	nickname := ctx.UserValue("nickname").(string)
+
+	err := p.permissionUsecase.GrantModerator(reqCtx, slug, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
+
+func (p PermissionDelivery) RevokeModerator(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	nickname := ctx.UserValue("nickname").(string)
+
+	err := p.permissionUsecase.RevokeModerator(reqCtx, slug, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
+
+func (p PermissionDelivery) GetMembers(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+
+	members, err := p.permissionUsecase.GetMembers(reqCtx, slug)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, members)
+}
+
+func (p PermissionDelivery) AddMember(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	nickname := ctx.UserValue("nickname").(string)
+
+	member, err := p.permissionUsecase.AddMember(reqCtx, slug, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, member)
+}
+
+func (p PermissionDelivery) RemoveMember(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, p.timeout)
+	defer cancel()
+
+	slug := ctx.UserValue("slug").(string)
+	nickname := ctx.UserValue("nickname").(string)
+
+	err := p.permissionUsecase.RemoveMember(reqCtx, slug, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
@@ -0,0 +1,16 @@
+package permission
+
+import "context"
+
+type operatorKey struct{}
+
+// Operator marks ctx as coming from the command line, which has the same power
+// as whoever can reach the database.
+func Operator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey{}, true)
+}
+
+func IsOperator(ctx context.Context) bool {
+	operator, _ := ctx.Value(operatorKey{}).(bool)
+	return operator
+}
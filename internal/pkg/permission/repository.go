@@ -0,0 +1,54 @@
+package permission
+
+import (
+	"context"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+// Global roles stored in users.role. Moderation is granted per forum instead.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+var (
+	ErrNotAdmin          = apperror.New(apperror.ErrForbidden, "not_admin", "only administrators can do this")
+	ErrNotModerator      = apperror.New(apperror.ErrForbidden, "not_moderator", "only moderators of the forum and administrators can do this")
+	ErrNotAuthor         = apperror.New(apperror.ErrForbidden, "not_author", "only the author, moderators of the forum and administrators can do this")
+	ErrNotForumOwner     = apperror.New(apperror.ErrForbidden, "not_forum_owner", "only the forum owner and administrators can do this")
+	ErrModeratorNotFound = apperror.New(apperror.ErrNotFound, "moderator_not_found", "user doesn't moderate the forum")
+	ErrOwnerIsModerator  = apperror.New(apperror.ErrConflict, "owner_is_moderator", "the forum owner always moderates it")
+	ErrInvalidRole       = apperror.New(apperror.ErrValidation, "invalid_role", "role must be member or admin")
+	ErrNotMember         = apperror.New(apperror.ErrForbidden, "not_member", "only members of the forum can do this")
+	ErrMemberNotFound    = apperror.New(apperror.ErrNotFound, "member_not_found", "user is neither a member of the forum nor invited to it")
+	// ErrPrivateForum looks the same as a forum that doesn't exist.
+	ErrPrivateForum = apperror.New(apperror.ErrNotFound, "forum_not_found", "forum doesn't exist")
+)
+
+// Membership statuses of forum_members.
+const (
+	MemberInvited   = "invited"
+	MemberRequested = "requested"
+	MemberActive    = "member"
+)
+
+type Repository interface {
+	GetRole(ctx context.Context, nickname string) (string, error)
+	SetRole(ctx context.Context, nickname string, role string) error
+	GetForumOwner(ctx context.Context, slug string) (string, error)
+	// IsModerator is true for the forum owner too.
+	IsModerator(ctx context.Context, slug string, nickname string) (bool, error)
+	// GetModerators lists the owner first, then the granted moderators by nickname.
+	GetModerators(ctx context.Context, slug string) ([]models.Moderator, error)
+	GrantModerator(ctx context.Context, slug string, nickname string) error
+	RevokeModerator(ctx context.Context, slug string, nickname string) error
+	GetVisibility(ctx context.Context, slug string) (string, error)
+	// GetMember fails with ErrMemberNotFound for users without a membership row.
+	GetMember(ctx context.Context, slug string, nickname string) (models.Member, error)
+	// GetMembers lists members, invitations and requests by nickname.
+	GetMembers(ctx context.Context, slug string) ([]models.Member, error)
+	SetMember(ctx context.Context, slug string, nickname string, status string) (models.Member, error)
+	RemoveMember(ctx context.Context, slug string, nickname string) error
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/metrics"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/aanufriev/forum/internal/pkg/statements"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+type PermissionRepository struct {
+	stmts permissionStatements
+}
+
+type permissionStatements struct {
+	getRole         *sql.Stmt
+	setRole         *sql.Stmt
+	getForumOwner   *sql.Stmt
+	isModerator     *sql.Stmt
+	getModerators   *sql.Stmt
+	grantModerator  *sql.Stmt
+	revokeModerator *sql.Stmt
+	getVisibility   *sql.Stmt
+	getMember       *sql.Stmt
+	getMembers      *sql.Stmt
+	setMember       *sql.Stmt
+	removeMember    *sql.Stmt
+}
+
+const selectMember = `SELECT u.nickname, m.status, m.created FROM forum_members AS m
+	JOIN users AS u ON u.id = m.user_id`
+
+// New prepares the repository's statements, so the schema must already be migrated.
+func New(ctx context.Context, db *sql.DB) (permission.Repository, error) {
+	var s permissionStatements
+	err := statements.Prepare(
+		ctx, db,
+		statements.Statement{Dest: &s.getRole, Query: "SELECT role FROM users WHERE nickname = $1"},
+		statements.Statement{Dest: &s.setRole, Query: "UPDATE users SET role = $2 WHERE nickname = $1"},
+		statements.Statement{Dest: &s.getForumOwner, Query: "SELECT user_nickname FROM forums WHERE slug = $1"},
+		statements.Statement{Dest: &s.isModerator, Query: `SELECT
+		EXISTS(SELECT 1 FROM forums WHERE slug = $1 AND user_nickname = $2)
+		OR EXISTS(
+			SELECT 1 FROM forum_moderators AS m
+			JOIN users AS u ON u.id = m.user_id
+			WHERE m.forum_slug = $1 AND u.nickname = $2
+		)`},
+		statements.Statement{Dest: &s.getModerators, Query: `SELECT user_nickname, true FROM forums WHERE slug = $1
+		UNION ALL
+		(SELECT u.nickname, false FROM forum_moderators AS m
+		JOIN users AS u ON u.id = m.user_id
+		JOIN forums AS f ON f.slug = m.forum_slug
+		WHERE m.forum_slug = $1 AND u.nickname <> f.user_nickname
+		ORDER BY u.nickname)`},
+		statements.Statement{Dest: &s.grantModerator, Query: `INSERT INTO forum_moderators (forum_slug, user_id)
+		SELECT f.slug, u.id FROM forums AS f, users AS u
+		WHERE f.slug = $1 AND u.nickname = $2
+		ON CONFLICT DO NOTHING`},
+		statements.Statement{Dest: &s.revokeModerator, Query: `DELETE FROM forum_moderators AS m
+		USING users AS u
+		WHERE u.id = m.user_id AND m.forum_slug = $1 AND u.nickname = $2`},
+		statements.Statement{Dest: &s.getVisibility, Query: "SELECT visibility FROM forums WHERE slug = $1"},
+		statements.Statement{Dest: &s.getMember, Query: selectMember + " WHERE m.forum_slug = $1 AND u.nickname = $2"},
+		statements.Statement{Dest: &s.getMembers, Query: selectMember + " WHERE m.forum_slug = $1 ORDER BY u.nickname"},
+		// The row keeps its creation time when the status changes.
+		statements.Statement{Dest: &s.setMember, Query: `WITH target AS (
+			SELECT f.slug, u.id, u.nickname FROM forums AS f, users AS u
+			WHERE f.slug = $1 AND u.nickname = $2
+		), upsert AS (
+			INSERT INTO forum_members (forum_slug, user_id, status)
+			SELECT slug, id, $3 FROM target
+			ON CONFLICT (forum_slug, user_id) DO UPDATE SET status = excluded.status
+			RETURNING status, created
+		)
+		SELECT t.nickname, m.status, m.created FROM target AS t, upsert AS m`},
+		statements.Statement{Dest: &s.removeMember, Query: `DELETE FROM forum_members AS m
+		USING users AS u
+		WHERE u.id = m.user_id AND m.forum_slug = $1 AND u.nickname = $2`},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return PermissionRepository{
+		stmts: s,
+	}, nil
+}
+
+func (p PermissionRepository) GetRole(ctx context.Context, nickname string) (string, error) {
+	defer metrics.ObserveQuery("permission", "GetRole", time.Now())
+
+	var role string
+	err := p.stmts.getRole.QueryRowContext(
+		ctx,
+		nickname,
+	).Scan(&role)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't get role of '%v'. Error: %w", nickname, err)
+	}
+
+	return role, nil
+}
+
+func (p PermissionRepository) SetRole(ctx context.Context, nickname string, role string) error {
+	defer metrics.ObserveQuery("permission", "SetRole", time.Now())
+
+	result, err := p.stmts.setRole.ExecContext(ctx, nickname, role)
+	if err != nil {
+		return fmt.Errorf("couldn't set role of '%v' to '%v'. Error: %w", nickname, role, err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+	}
+
+	return nil
+}
+
+func (p PermissionRepository) GetForumOwner(ctx context.Context, slug string) (string, error) {
+	defer metrics.ObserveQuery("permission", "GetForumOwner", time.Now())
+
+	var owner string
+	err := p.stmts.getForumOwner.QueryRowContext(
+		ctx,
+		slug,
+	).Scan(&owner)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't get owner of forum '%v'. Error: %w", slug, err)
+	}
+
+	return owner, nil
+}
+
+func (p PermissionRepository) IsModerator(ctx context.Context, slug string, nickname string) (bool, error) {
+	defer metrics.ObserveQuery("permission", "IsModerator", time.Now())
+
+	var isModerator bool
+	err := p.stmts.isModerator.QueryRowContext(
+		ctx,
+		slug, nickname,
+	).Scan(&isModerator)
+
+	if err != nil {
+		return false, fmt.Errorf("couldn't check if '%v' moderates forum '%v'. Error: %w", nickname, slug, err)
+	}
+
+	return isModerator, nil
+}
+
+func (p PermissionRepository) GetModerators(ctx context.Context, slug string) ([]models.Moderator, error) {
+	defer metrics.ObserveQuery("permission", "GetModerators", time.Now())
+
+	rows, err := p.stmts.getModerators.QueryContext(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get moderators of forum '%v'. Error: %w", slug, err)
+	}
+	defer rows.Close()
+
+	moderators := make([]models.Moderator, 0, 1)
+	var moderator models.Moderator
+	for rows.Next() {
+		err = rows.Scan(&moderator.Nickname, &moderator.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		moderators = append(moderators, moderator)
+	}
+
+	return moderators, rows.Err()
+}
+
+// GrantModerator does nothing if the user already moderates the forum.
+func (p PermissionRepository) GrantModerator(ctx context.Context, slug string, nickname string) error {
+	defer metrics.ObserveQuery("permission", "GrantModerator", time.Now())
+
+	_, err := p.stmts.grantModerator.ExecContext(ctx, slug, nickname)
+	if err != nil {
+		return fmt.Errorf("couldn't make '%v' a moderator of forum '%v'. Error: %w", nickname, slug, err)
+	}
+
+	return nil
+}
+
+func (p PermissionRepository) RevokeModerator(ctx context.Context, slug string, nickname string) error {
+	defer metrics.ObserveQuery("permission", "RevokeModerator", time.Now())
+
+	result, err := p.stmts.revokeModerator.ExecContext(ctx, slug, nickname)
+	if err != nil {
+		return fmt.Errorf("couldn't revoke moderation of forum '%v' from '%v'. Error: %w", slug, nickname, err)
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if revoked == 0 {
+		return fmt.Errorf("'%v' in forum '%v': %w", nickname, slug, permission.ErrModeratorNotFound)
+	}
+
+	return nil
+}
+
+func (p PermissionRepository) GetVisibility(ctx context.Context, slug string) (string, error) {
+	defer metrics.ObserveQuery("permission", "GetVisibility", time.Now())
+
+	var visibility string
+	err := p.stmts.getVisibility.QueryRowContext(
+		ctx,
+		slug,
+	).Scan(&visibility)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find forum with slug '%v': %w", slug, forum.ErrForumDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't get visibility of forum '%v'. Error: %w", slug, err)
+	}
+
+	return visibility, nil
+}
+
+func (p PermissionRepository) GetMember(ctx context.Context, slug string, nickname string) (models.Member, error) {
+	defer metrics.ObserveQuery("permission", "GetMember", time.Now())
+
+	var member models.Member
+	err := p.stmts.getMember.QueryRowContext(
+		ctx,
+		slug, nickname,
+	).Scan(&member.Nickname, &member.Status, &member.Since)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Member{}, fmt.Errorf("'%v' in forum '%v': %w", nickname, slug, permission.ErrMemberNotFound)
+		}
+		return models.Member{}, fmt.Errorf("couldn't get membership of '%v' in forum '%v'. Error: %w", nickname, slug, err)
+	}
+
+	return member, nil
+}
+
+func (p PermissionRepository) GetMembers(ctx context.Context, slug string) ([]models.Member, error) {
+	defer metrics.ObserveQuery("permission", "GetMembers", time.Now())
+
+	rows, err := p.stmts.getMembers.QueryContext(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get members of forum '%v'. Error: %w", slug, err)
+	}
+	defer rows.Close()
+
+	members := make([]models.Member, 0)
+	var member models.Member
+	for rows.Next() {
+		err = rows.Scan(&member.Nickname, &member.Status, &member.Since)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (p PermissionRepository) SetMember(ctx context.Context, slug string, nickname string, status string) (models.Member, error) {
+	defer metrics.ObserveQuery("permission", "SetMember", time.Now())
+
+	var member models.Member
+	err := p.stmts.setMember.QueryRowContext(
+		ctx,
+		slug, nickname, status,
+	).Scan(&member.Nickname, &member.Status, &member.Since)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Member{}, fmt.Errorf("can't find user '%v' or forum '%v': %w", nickname, slug, user.ErrUserDoesntExists)
+		}
+		return models.Member{}, fmt.Errorf("couldn't make '%v' %v of forum '%v'. Error: %w", nickname, status, slug, err)
+	}
+
+	return member, nil
+}
+
+func (p PermissionRepository) RemoveMember(ctx context.Context, slug string, nickname string) error {
+	defer metrics.ObserveQuery("permission", "RemoveMember", time.Now())
+
+	result, err := p.stmts.removeMember.ExecContext(ctx, slug, nickname)
+	if err != nil {
+		return fmt.Errorf("couldn't remove '%v' from forum '%v'. Error: %w", nickname, slug, err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("'%v' in forum '%v': %w", nickname, slug, permission.ErrMemberNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package permission
+
+import (
+	"context"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+// Usecase answers whether the request in ctx may do something. Requests act as
+// the actor of their session; operator contexts may do anything, and so may
+// requests without a session in auth-compat mode, short of RequireAdmin and RequireSelf.
+type Usecase interface {
+	RequireAdmin(ctx context.Context) error
+	RequireModerator(ctx context.Context, slug string) error
+	// RequireOwner lets the owner of forum slug and administrators through.
+	RequireOwner(ctx context.Context, slug string) error
+	// RequireAuthor lets the author, moderators of forum slug and administrators through.
+	RequireAuthor(ctx context.Context, author string, slug string) error
+	// RequireSelf lets the user nickname and administrators through.
+	RequireSelf(ctx context.Context, nickname string) error
+	SetRole(ctx context.Context, nickname string, role string) error
+	GetModerators(ctx context.Context, slug string) ([]models.Moderator, error)
+	GrantModerator(ctx context.Context, slug string, nickname string) error
+	RevokeModerator(ctx context.Context, slug string, nickname string) error
+	// RequireReader and RequireWriter let members, moderators and administrators
+	// into restricted and private forums. Non-members get ErrPrivateForum from
+	// private forums and ErrNotMember when writing to restricted ones.
+	RequireReader(ctx context.Context, slug string) error
+	RequireWriter(ctx context.Context, slug string) error
+	GetMembers(ctx context.Context, slug string) ([]models.Member, error)
+	// AddMember invites the user when a moderator asks and files a request when
+	// the user asks; the other side asking too makes them a member.
+	AddMember(ctx context.Context, slug string, nickname string) (models.Member, error)
+	// RemoveMember is for moderators and the user themself.
+	RemoveMember(ctx context.Context, slug string, nickname string) error
+}
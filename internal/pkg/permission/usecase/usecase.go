@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+type PermissionUsecase struct {
+	permissionRepository permission.Repository
+	compat               bool
+}
+
+// New builds the usecase. With compat, requests without a session token
+// keep the unrestricted access they had before authentication existed,
+// except to administrative operations.
+func New(permissionRepository permission.Repository, compat bool) permission.Usecase {
+	return PermissionUsecase{
+		permissionRepository: permissionRepository,
+		compat:               compat,
+	}
+}
+
+// actor returns who ctx acts as, or unrestricted for operators and compat requests.
+func (p PermissionUsecase) actor(ctx context.Context) (nickname string, unrestricted bool, err error) {
+	if permission.IsOperator(ctx) {
+		return "", true, nil
+	}
+
+	nickname, ok := requestctx.Actor(ctx)
+	if !ok {
+		if p.compat {
+			return "", true, nil
+		}
+		return "", false, auth.ErrUnauthenticated
+	}
+
+	return nickname, false, nil
+}
+
+// privilegedActor is actor for administrative operations, which compat doesn't open up:
+// clearing the service, changing roles, deleting accounts and importing users.
+func (p PermissionUsecase) privilegedActor(ctx context.Context) (nickname string, unrestricted bool, err error) {
+	nickname, unrestricted, err = p.actor(ctx)
+	if unrestricted && !permission.IsOperator(ctx) {
+		return "", false, auth.ErrUnauthenticated
+	}
+
+	return nickname, unrestricted, err
+}
+
+func (p PermissionUsecase) isAdmin(ctx context.Context, nickname string) (bool, error) {
+	role, err := p.permissionRepository.GetRole(ctx, nickname)
+	if errors.Is(err, user.ErrUserDoesntExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return role == permission.RoleAdmin, nil
+}
+
+func (p PermissionUsecase) RequireAdmin(ctx context.Context) error {
+	nickname, unrestricted, err := p.privilegedActor(ctx)
+	if err != nil || unrestricted {
+		return err
+	}
+
+	isAdmin, err := p.isAdmin(ctx, nickname)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return fmt.Errorf("'%v' is not an administrator: %w", nickname, permission.ErrNotAdmin)
+	}
+
+	return nil
+}
+
+// canModerate is true for administrators and moderators of the forum, including its owner.
+func (p PermissionUsecase) canModerate(ctx context.Context, nickname string, slug string) (bool, error) {
+	isAdmin, err := p.isAdmin(ctx, nickname)
+	if err != nil || isAdmin {
+		return isAdmin, err
+	}
+
+	return p.permissionRepository.IsModerator(ctx, slug, nickname)
+}
+
+func (p PermissionUsecase) RequireModerator(ctx context.Context, slug string) error {
+	nickname, unrestricted, err := p.actor(ctx)
+	if err != nil || unrestricted {
+		return err
+	}
+
+	allowed, err := p.canModerate(ctx, nickname, slug)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("'%v' doesn't moderate forum '%v': %w", nickname, slug, permission.ErrNotModerator)
+	}
+
+	return nil
+}
+
+func (p PermissionUsecase) RequireAuthor(ctx context.Context, author string, slug string) error {
+	nickname, unrestricted, err := p.actor(ctx)
+	if err != nil || unrestricted || strings.EqualFold(nickname, author) {
+		return err
+	}
+
+	allowed, err := p.canModerate(ctx, nickname, slug)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("'%v' acts on content of '%v' in forum '%v': %w", nickname, author, slug, permission.ErrNotAuthor)
+	}
+
+	return nil
+}
+
+func (p PermissionUsecase) RequireSelf(ctx context.Context, nickname string) error {
+	actor, unrestricted, err := p.privilegedActor(ctx)
+	if err != nil || unrestricted || strings.EqualFold(actor, nickname) {
+		return err
+	}
+
+	isAdmin, err := p.isAdmin(ctx, actor)
+	if err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return fmt.Errorf("'%v' acts on the account of '%v': %w", actor, nickname, permission.ErrNotAdmin)
+	}
+
+	return nil
+}
+
+func (p PermissionUsecase) SetRole(ctx context.Context, nickname string, role string) error {
+	if role != permission.RoleMember && role != permission.RoleAdmin {
+		return fmt.Errorf("role '%v': %w", role, permission.ErrInvalidRole)
+	}
+
+	err := p.RequireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return p.permissionRepository.SetRole(ctx, nickname, role)
+}
+
+func (p PermissionUsecase) GetModerators(ctx context.Context, slug string) ([]models.Moderator, error) {
+	err := p.RequireReader(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.permissionRepository.GetModerators(ctx, slug)
+}
+
+func (p PermissionUsecase) RequireOwner(ctx context.Context, slug string) error {
+	_, err := p.requireOwner(ctx, slug)
+	return err
+}
+
+// requireOwner returns the forum's owner if ctx may act as them.
+func (p PermissionUsecase) requireOwner(ctx context.Context, slug string) (string, error) {
+	owner, err := p.permissionRepository.GetForumOwner(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+
+	nickname, unrestricted, err := p.actor(ctx)
+	if err != nil || unrestricted || strings.EqualFold(nickname, owner) {
+		return owner, err
+	}
+
+	isAdmin, err := p.isAdmin(ctx, nickname)
+	if err != nil {
+		return "", err
+	}
+
+	if !isAdmin {
+		return "", fmt.Errorf("'%v' doesn't own forum '%v': %w", nickname, slug, permission.ErrNotForumOwner)
+	}
+
+	return owner, nil
+}
+
+func (p PermissionUsecase) GrantModerator(ctx context.Context, slug string, nickname string) error {
+	_, err := p.requireOwner(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	// Fails for unknown users, the insert alone wouldn't tell.
+	_, err = p.permissionRepository.GetRole(ctx, nickname)
+	if err != nil {
+		return err
+	}
+
+	return p.permissionRepository.GrantModerator(ctx, slug, nickname)
+}
+
+func (p PermissionUsecase) RevokeModerator(ctx context.Context, slug string, nickname string) error {
+	owner, err := p.requireOwner(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(owner, nickname) {
+		return fmt.Errorf("'%v' owns forum '%v': %w", nickname, slug, permission.ErrOwnerIsModerator)
+	}
+
+	return p.permissionRepository.RevokeModerator(ctx, slug, nickname)
+}
+
+func (p PermissionUsecase) RequireReader(ctx context.Context, slug string) error {
+	return p.requireAccess(ctx, slug, false)
+}
+
+func (p PermissionUsecase) RequireWriter(ctx context.Context, slug string) error {
+	return p.requireAccess(ctx, slug, true)
+}
+
+func (p PermissionUsecase) requireAccess(ctx context.Context, slug string, write bool) error {
+	visibility, err := p.permissionRepository.GetVisibility(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if visibility == forum.VisibilityPublic || visibility == forum.VisibilityRestricted && !write {
+		return nil
+	}
+
+	// Non-members get the error of a missing forum, word for word.
+	hidden := fmt.Errorf("can't find forum with slug '%v': %w", slug, permission.ErrPrivateForum)
+
+	nickname, unrestricted, err := p.actor(ctx)
+	if unrestricted {
+		return nil
+	}
+	if err != nil {
+		if visibility == forum.VisibilityPrivate {
+			return hidden
+		}
+		return err
+	}
+
+	allowed, err := p.isMember(ctx, nickname, slug)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		if visibility == forum.VisibilityPrivate {
+			return hidden
+		}
+		return fmt.Errorf("'%v' is not a member of forum '%v': %w", nickname, slug, permission.ErrNotMember)
+	}
+
+	return nil
+}
+
+// isMember is true for members of the forum, its moderators and administrators.
+func (p PermissionUsecase) isMember(ctx context.Context, nickname string, slug string) (bool, error) {
+	member, err := p.permissionRepository.GetMember(ctx, slug, nickname)
+	if err == nil && member.Status == permission.MemberActive {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, permission.ErrMemberNotFound) {
+		return false, err
+	}
+
+	return p.canModerate(ctx, nickname, slug)
+}
+
+func (p PermissionUsecase) GetMembers(ctx context.Context, slug string) ([]models.Member, error) {
+	err := p.RequireReader(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.RequireModerator(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.permissionRepository.GetMembers(ctx, slug)
+}
+
+func (p PermissionUsecase) AddMember(ctx context.Context, slug string, nickname string) (models.Member, error) {
+	visibility, err := p.permissionRepository.GetVisibility(ctx, slug)
+	if err != nil {
+		return models.Member{}, err
+	}
+
+	moderator, err := p.moderatesOrIs(ctx, slug, visibility, nickname)
+	if err != nil {
+		return models.Member{}, err
+	}
+
+	// Fails for unknown users, the lookup alone wouldn't tell.
+	_, err = p.permissionRepository.GetRole(ctx, nickname)
+	if err != nil {
+		return models.Member{}, err
+	}
+
+	member, err := p.permissionRepository.GetMember(ctx, slug, nickname)
+	if err != nil && !errors.Is(err, permission.ErrMemberNotFound) {
+		return models.Member{}, err
+	}
+
+	status := member.Status
+	switch {
+	case moderator && status == "":
+		status = permission.MemberInvited
+	case !moderator && status == "":
+		// Never reached for private forums, which are invite-only.
+		status = permission.MemberRequested
+	case moderator && status == permission.MemberRequested, !moderator && status == permission.MemberInvited:
+		status = permission.MemberActive
+	}
+
+	if status == member.Status {
+		return member, nil
+	}
+
+	return p.permissionRepository.SetMember(ctx, slug, nickname, status)
+}
+
+func (p PermissionUsecase) RemoveMember(ctx context.Context, slug string, nickname string) error {
+	visibility, err := p.permissionRepository.GetVisibility(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.moderatesOrIs(ctx, slug, visibility, nickname)
+	if err != nil {
+		return err
+	}
+
+	return p.permissionRepository.RemoveMember(ctx, slug, nickname)
+}
+
+// moderatesOrIs lets moderators of the forum and the user nickname through,
+// telling which of them ctx acts as. Unrestricted contexts act as moderators.
+// Private forums only admit they exist to users with a membership row.
+func (p PermissionUsecase) moderatesOrIs(ctx context.Context, slug string, visibility string, nickname string) (moderator bool, err error) {
+	actor, unrestricted, err := p.actor(ctx)
+	if err != nil || unrestricted {
+		return unrestricted, err
+	}
+
+	moderator, err = p.canModerate(ctx, actor, slug)
+	if err != nil || moderator {
+		return moderator, err
+	}
+
+	if visibility == forum.VisibilityPrivate {
+		_, err = p.permissionRepository.GetMember(ctx, slug, actor)
+		if errors.Is(err, permission.ErrMemberNotFound) {
+			return false, fmt.Errorf("can't find forum with slug '%v': %w", slug, permission.ErrPrivateForum)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if !strings.EqualFold(actor, nickname) {
+		return false, fmt.Errorf("'%v' acts on membership of '%v' in forum '%v': %w", actor, nickname, slug, permission.ErrNotModerator)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+)
+
+// privateForum is a private forum owned by bob with alice as its only member.
+type privateForum struct {
+	permission.Repository
+}
+
+func (privateForum) GetVisibility(ctx context.Context, slug string) (string, error) {
+	return forum.VisibilityPrivate, nil
+}
+
+func (privateForum) GetRole(ctx context.Context, nickname string) (string, error) {
+	return permission.RoleMember, nil
+}
+
+func (privateForum) IsModerator(ctx context.Context, slug string, nickname string) (bool, error) {
+	return nickname == "bob", nil
+}
+
+func (privateForum) GetMember(ctx context.Context, slug string, nickname string) (models.Member, error) {
+	if nickname == "alice" {
+		return models.Member{Nickname: nickname, Status: permission.MemberActive}, nil
+	}
+
+	return models.Member{}, permission.ErrMemberNotFound
+}
+
+func (privateForum) GetModerators(ctx context.Context, slug string) ([]models.Moderator, error) {
+	return []models.Moderator{{Nickname: "bob"}}, nil
+}
+
+func TestGetModeratorsOfPrivateForum(t *testing.T) {
+	usecase := New(privateForum{}, false)
+
+	moderators, err := usecase.GetModerators(requestctx.WithActor(context.Background(), "alice"), "secret")
+	if err != nil || len(moderators) != 1 {
+		t.Errorf("member GetModerators() = %v, %v, want bob", moderators, err)
+	}
+
+	_, err = usecase.GetModerators(requestctx.WithActor(context.Background(), "eve"), "secret")
+	if !errors.Is(err, permission.ErrPrivateForum) {
+		t.Errorf("stranger GetModerators() error = %v, want %v", err, permission.ErrPrivateForum)
+	}
+
+	_, err = usecase.GetModerators(context.Background(), "secret")
+	if !errors.Is(err, permission.ErrPrivateForum) {
+		t.Errorf("anonymous GetModerators() error = %v, want %v", err, permission.ErrPrivateForum)
+	}
+}
+
+func TestCompatKeepsAdministrationBehindAdmins(t *testing.T) {
+	usecase := New(privateForum{}, true)
+	anonymous := context.Background()
+
+	if err := usecase.RequireWriter(anonymous, "secret"); err != nil {
+		t.Errorf("anonymous RequireWriter() error = %v, want compat to let it through", err)
+	}
+
+	if err := usecase.RequireAdmin(anonymous); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("anonymous RequireAdmin() error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+	if err := usecase.RequireSelf(anonymous, "bob"); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("anonymous RequireSelf() error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+	if err := usecase.SetRole(anonymous, "bob", permission.RoleAdmin); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("anonymous SetRole() error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+
+	if err := usecase.RequireAdmin(permission.Operator(anonymous)); err != nil {
+		t.Errorf("operator RequireAdmin() error = %v", err)
+	}
+}
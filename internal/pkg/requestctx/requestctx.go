@@ -0,0 +1,55 @@
+package requestctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/aanufriev/forum/configs"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	requestIDKey struct{}
+	actorKey     struct{}
+)
+
+// New derives the context passed to usecases for a single request.
+// It deliberately does not inherit from ctx: fasthttp cancels that one as soon
+// as shutdown starts, which would abort the requests we are trying to drain.
+//
+// A client that disconnects doesn't cancel it either. fasthttp only notices a
+// closed connection when it reads the next request or writes the response, and
+// reading the connection from a handler would consume pipelined requests, so
+// timeout is what bounds the work done for a client that went away.
+func New(ctx *fasthttp.RequestCtx, timeout time.Duration) (context.Context, context.CancelFunc) {
+	reqCtx := context.Background()
+	if reqID, ok := ctx.UserValue(configs.RequestID).(string); ok {
+		reqCtx = context.WithValue(reqCtx, requestIDKey{}, reqID)
+	}
+
+	if actor, ok := ctx.UserValue(configs.Actor).(string); ok {
+		reqCtx = WithActor(reqCtx, actor)
+	}
+
+	if timeout <= 0 {
+		return context.WithCancel(reqCtx)
+	}
+
+	return context.WithTimeout(reqCtx, timeout)
+}
+
+func RequestID(ctx context.Context) string {
+	reqID, _ := ctx.Value(requestIDKey{}).(string)
+	return reqID
+}
+
+// WithActor records the nickname an authenticated request acts as.
+func WithActor(ctx context.Context, nickname string) context.Context {
+	return context.WithValue(ctx, actorKey{}, nickname)
+}
+
+// Actor returns the nickname proven by the session token, if there was one.
+func Actor(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
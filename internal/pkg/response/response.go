@@ -0,0 +1,77 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	InternalErrorCode = "internal_error"
+	TimeoutCode       = "timeout"
+)
+
+func JSON(ctx *fasthttp.RequestCtx, status int, body interface{}) {
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+
+	err := json.NewEncoder(ctx).Encode(body)
+	if err != nil {
+		ctx.SetStatusCode(http.StatusInternalServerError)
+	}
+}
+
+// Error writes err as a JSON error envelope. Domain errors keep their message
+// and code, anything else is logged and reported as an opaque 500.
+func Error(ctx *fasthttp.RequestCtx, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || apperror.IsQueryCanceled(err) {
+		JSON(ctx, http.StatusServiceUnavailable, models.Message{
+			Text: "Request took too long",
+			Code: TimeoutCode,
+		})
+		return
+	}
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		logrus.WithFields(logrus.Fields{
+			configs.RequestID: ctx.UserValue(configs.RequestID),
+			"path":            string(ctx.Path()),
+		}).Error(err)
+
+		JSON(ctx, http.StatusInternalServerError, models.Message{
+			Text: "Internal server error",
+			Code: InternalErrorCode,
+		})
+		return
+	}
+
+	JSON(ctx, Status(appErr), models.Message{
+		Text: err.Error(),
+		Code: appErr.Code,
+	})
+}
+
+func Status(err error) int {
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperror.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperror.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperror.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperror.ErrUnauthorized):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+)
+
+func TestStatus(t *testing.T) {
+	notFound := apperror.New(apperror.ErrNotFound, "thing_not_found", "thing doesn't exist")
+
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{notFound, http.StatusNotFound},
+		{apperror.New(apperror.ErrConflict, "thing_conflict", "thing conflicts"), http.StatusConflict},
+		{apperror.ErrInvalidBody, http.StatusBadRequest},
+		{apperror.New(apperror.ErrForbidden, "thing_forbidden", "thing is forbidden"), http.StatusForbidden},
+		{apperror.New(apperror.ErrUnauthorized, "thing_unauthorized", "who are you"), http.StatusUnauthorized},
+		{fmt.Errorf("post 1: %w", fmt.Errorf("thread 2: %w", notFound)), http.StatusNotFound},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+		{apperror.New(errors.New("strange"), "strange", "strange"), http.StatusInternalServerError},
+	} {
+		if got := Status(tt.err); got != tt.want {
+			t.Errorf("Status(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package statements
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Statement binds a query to the field that will hold its prepared form.
+type Statement struct {
+	Dest  **sql.Stmt
+	Query string
+}
+
+// Ordered is a pair of statements that differ only in sort direction.
+type Ordered struct {
+	Asc  *sql.Stmt
+	Desc *sql.Stmt
+}
+
+func (o Ordered) Pick(desc bool) *sql.Stmt {
+	if desc {
+		return o.Desc
+	}
+	return o.Asc
+}
+
+// Prepare prepares every statement once so that calls only bind arguments.
+// If one of them fails, the ones already prepared are closed.
+func Prepare(ctx context.Context, db *sql.DB, stmts ...Statement) error {
+	for i, s := range stmts {
+		stmt, err := db.PrepareContext(ctx, s.Query)
+		if err != nil {
+			for _, prepared := range stmts[:i] {
+				_ = (*prepared.Dest).Close()
+			}
+			return fmt.Errorf("couldn't prepare statement %q. Error: %w", s.Query, err)
+		}
+
+		*s.Dest = stmt
+	}
+
+	return nil
+}
+
+// Limit turns 0 into NULL, which postgres reads as LIMIT ALL.
+func Limit(limit int) interface{} {
+	if limit == 0 {
+		return nil
+	}
+	return limit
+}
+
+// Nullable turns "" into NULL, for optional filters.
+func Nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// likeEscaper makes user input match literally inside a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike quotes the LIKE wildcards in value.
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,18 @@
+package statements
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	for value, want := range map[string]string{
+		"golang": "golang",
+		"50%":    `50\%`,
+		"a_b":    `a\_b`,
+		`a\`:     `a\\`,
+		`%_\`:    `\%\_\\`,
+		"":       "",
+	} {
+		if got := EscapeLike(value); got != want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
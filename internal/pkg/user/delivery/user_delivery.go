@@ -1,138 +1,374 @@
 package delivery
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/aanufriev/forum/configs"
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/auth"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/requestctx"
+	"github.com/aanufriev/forum/internal/pkg/response"
 	"github.com/aanufriev/forum/internal/pkg/user"
 	"github.com/valyala/fasthttp"
 )
 
 type UserDelivery struct {
-	userUsecase user.Usecase
+	userUsecase   user.Usecase
+	guard         auth.Guard
+	timeout       time.Duration
+	importTimeout time.Duration
 }
 
-func New(userUsecase user.Usecase) UserDelivery {
+// New builds the handlers. Imports get importTimeout instead of timeout.
+func New(userUsecase user.Usecase, guard auth.Guard, timeout time.Duration, importTimeout time.Duration) UserDelivery {
 	return UserDelivery{
-		userUsecase: userUsecase,
+		userUsecase:   userUsecase,
+		guard:         guard,
+		timeout:       timeout,
+		importTimeout: importTimeout,
 	}
 }
 
 func (u UserDelivery) Create(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
 	nickname := ctx.UserValue("nickname").(string)
 
-	var user models.User
-	err := user.UnmarshalJSON(ctx.PostBody())
+	var profile models.User
+	err := profile.UnmarshalJSON(ctx.PostBody())
+	if err != nil || profile.Email == nil {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+	// Only the profile fields are taken from the body.
+	profile = models.User{
+		Nickname: nickname,
+		Fullname: profile.Fullname,
+		Email:    profile.Email,
+		About:    profile.About,
+	}
+
+	var credentials models.Credentials
+	err = credentials.UnmarshalJSON(ctx.PostBody())
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+
+	if credentials.Password == "" && !u.guard.Compat {
+		response.Error(ctx, auth.ErrPasswordRequired)
 		return
 	}
-	user.Nickname = nickname
 
-	err = u.userUsecase.Create(user)
+	err = u.userUsecase.Create(reqCtx, profile, credentials.Password)
 	if err != nil {
-		users, err := u.userUsecase.GetUsersWithNicknameAndEmail(nickname, *user.Email)
-		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+		if !errors.Is(err, user.ErrDataConflict) {
+			response.Error(ctx, err)
 			return
 		}
 
-		ctx.SetStatusCode(http.StatusConflict)
-		err = json.NewEncoder(ctx).Encode(users)
+		users, err := u.userUsecase.GetUsersWithNicknameAndEmail(reqCtx, nickname, *profile.Email)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, err)
 			return
 		}
+
+		response.JSON(ctx, http.StatusConflict, users)
 		return
 	}
 
-	ctx.SetStatusCode(http.StatusCreated)
-	_ = json.NewEncoder(ctx).Encode(user)
+	response.JSON(ctx, http.StatusCreated, profile)
 }
 
 func (u UserDelivery) Get(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	nickname := ctx.UserValue("nickname").(string)
+
+	profile, err := u.userUsecase.Get(reqCtx, nickname)
+	if errors.Is(err, user.ErrUserDoesntExists) {
+		current, renamedErr := u.userUsecase.ResolveRenamed(reqCtx, nickname)
+		if renamedErr == nil {
+			// Temporary: the old nickname may be taken by someone else later.
+			ctx.Redirect("/api/user/"+url.PathEscape(current)+"/profile", http.StatusTemporaryRedirect)
+			return
+		}
+	}
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, profile)
+}
+
+func (u UserDelivery) Update(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
 	nickname := ctx.UserValue("nickname").(string)
 
-	profile, err := u.userUsecase.Get(nickname)
+	_, err := u.guard.Actor(reqCtx, nickname)
 	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
 
-		if errors.Is(err, user.ErrUserDoesntExists) {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg := models.Message{
-				Text: fmt.Sprintf("Can't find user with id #%v\n", nickname),
-			}
+	profile := models.User{}
+	err = profile.UnmarshalJSON(ctx.PostBody())
+	if err != nil {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+	profile = models.User{
+		Nickname: nickname,
+		Fullname: profile.Fullname,
+		Email:    profile.Email,
+		About:    profile.About,
+	}
 
-			err = json.NewEncoder(ctx).Encode(msg)
-			if err != nil {
-				ctx.SetStatusCode(http.StatusInternalServerError)
-				return
+	fullProfile, err := u.userUsecase.Update(reqCtx, profile)
+	if err != nil {
+		if errors.Is(err, user.ErrDataConflict) && profile.Email != nil {
+			emailOwnerNickname, ownerErr := u.userUsecase.GetUserNicknameWithEmail(reqCtx, *profile.Email)
+			if ownerErr == nil {
+				err = fmt.Errorf("this email is already registered by user '%v': %w", emailOwnerNickname, user.ErrDataConflict)
 			}
-			return
 		}
 
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(profile)
+	response.JSON(ctx, http.StatusOK, fullProfile)
+}
+
+func (u UserDelivery) Rename(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	nickname := ctx.UserValue("nickname").(string)
+
+	_, err := u.guard.Actor(reqCtx, nickname)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	var rename models.Rename
+	err = rename.UnmarshalJSON(ctx.PostBody())
+	if err != nil {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+
+	profile, err := u.userUsecase.Rename(reqCtx, nickname, rename.Nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, profile)
 }
 
-func (u UserDelivery) Update(ctx *fasthttp.RequestCtx) {
-	ctx.SetContentType("application/json")
+// Delete anonymizes the user unless mode=hard is given.
+// Anonymization answers with the tombstone profile, a hard delete with 204.
+func (u UserDelivery) Delete(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
 	nickname := ctx.UserValue("nickname").(string)
 
-	profile := models.User{}
-	err := profile.UnmarshalJSON(ctx.PostBody())
+	mode := string(ctx.QueryArgs().Peek("mode"))
+	if mode == "" {
+		mode = user.DeleteModeAnonymize
+	}
+
+	profile, err := u.userUsecase.Delete(reqCtx, nickname, mode)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusBadRequest)
+		response.Error(ctx, err)
 		return
 	}
-	profile.Nickname = nickname
 
-	fullProfile, err := u.userUsecase.Update(profile)
-	if err != nil {
-		var msg models.Message
-		if errors.Is(err, user.ErrUserDoesntExists) {
-			ctx.SetStatusCode(http.StatusNotFound)
-			msg = models.Message{
-				Text: fmt.Sprintf("Can't find user with id #%v\n", nickname),
-			}
-		} else if errors.Is(err, user.ErrDataConflict) {
-			emailOwnerNickname, err := u.userUsecase.GetUserNicknameWithEmail(*profile.Email)
-			if err != nil {
-				ctx.SetStatusCode(http.StatusInternalServerError)
-				return
-			}
+	if mode == user.DeleteModeHard {
+		ctx.SetStatusCode(http.StatusNoContent)
+		return
+	}
 
-			ctx.SetStatusCode(http.StatusConflict)
-			msg = models.Message{
-				Text: fmt.Sprintf("This email is already registered by user: %v", emailOwnerNickname),
-			}
-		} else {
-			ctx.SetStatusCode(http.StatusInternalServerError)
-			return
-		}
+	response.JSON(ctx, http.StatusOK, profile)
+}
 
-		err = json.NewEncoder(ctx).Encode(msg)
+func (u UserDelivery) Search(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	args := ctx.QueryArgs()
+	params := user.SearchParams{
+		Query: string(args.Peek(configs.Query)),
+		Match: string(args.Peek(configs.Match)),
+		Forum: string(args.Peek(configs.Forum)),
+		Since: string(args.Peek(configs.Since)),
+	}
+
+	limitParam := string(args.Peek(configs.Limit))
+	if limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
 		if err != nil {
-			ctx.SetStatusCode(http.StatusInternalServerError)
+			response.Error(ctx, fmt.Errorf("limit '%v' is not a number: %w", limitParam, apperror.ErrInvalidParam))
 			return
 		}
+		params.Limit = limit
+	}
+
+	descParam := string(args.Peek(configs.Desc))
+	switch descParam {
+	case "", "false":
+	case "true":
+		params.Desc = true
+	default:
+		response.Error(ctx, fmt.Errorf("desc '%v' is neither 'true' nor 'false': %w", descParam, apperror.ErrInvalidParam))
+		return
+	}
+
+	users, err := u.userUsecase.Search(reqCtx, params)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, users)
+}
+
+func (u UserDelivery) Verify(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	nickname := ctx.UserValue("nickname").(string)
+
+	var verification models.Verification
+	err := verification.UnmarshalJSON(ctx.PostBody())
+	if err != nil || verification.Token == "" {
+		response.Error(ctx, apperror.ErrInvalidBody)
+		return
+	}
+
+	profile, err := u.userUsecase.ConfirmEmail(reqCtx, nickname, verification.Token)
+	if err != nil {
+		response.Error(ctx, err)
 		return
 	}
 
-	err = json.NewEncoder(ctx).Encode(fullProfile)
+	response.JSON(ctx, http.StatusOK, profile)
+}
+
+func (u UserDelivery) ResendVerification(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	nickname := ctx.UserValue("nickname").(string)
+
+	_, err := u.guard.Actor(reqCtx, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	err = u.userUsecase.ResendVerification(reqCtx, nickname)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		response.Error(ctx, err)
 		return
 	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
+
+func (u UserDelivery) Ignore(ctx *fasthttp.RequestCtx) {
+	u.changeIgnore(ctx, u.userUsecase.Ignore)
+}
+
+func (u UserDelivery) Unignore(ctx *fasthttp.RequestCtx) {
+	u.changeIgnore(ctx, u.userUsecase.Unignore)
+}
+
+func (u UserDelivery) changeIgnore(ctx *fasthttp.RequestCtx, change func(ctx context.Context, nickname string, other string) error) {
+	reqCtx, cancel := requestctx.New(ctx, u.timeout)
+	defer cancel()
+
+	nickname := ctx.UserValue("nickname").(string)
+	other := ctx.UserValue("other").(string)
+
+	_, err := u.guard.Actor(reqCtx, nickname)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	err = change(reqCtx, nickname, other)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	ctx.SetStatusCode(http.StatusNoContent)
+}
+
+// Import takes a JSON array of users or one user per line (NDJSON).
+func (u UserDelivery) Import(ctx *fasthttp.RequestCtx) {
+	reqCtx, cancel := requestctx.New(ctx, u.importTimeout)
+	defer cancel()
+
+	entries, err := parseImport(ctx.PostBody())
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	report, err := u.userUsecase.Import(reqCtx, entries)
+	if err != nil {
+		response.Error(ctx, err)
+		return
+	}
+
+	response.JSON(ctx, http.StatusOK, report)
+}
+
+func parseImport(body []byte) ([]models.ImportedUser, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var entries models.ImportedUsers
+		err := entries.UnmarshalJSON(body)
+		if err != nil {
+			return nil, apperror.ErrInvalidBody
+		}
+		return entries, nil
+	}
+
+	entries := make([]models.ImportedUser, 0)
+	for n, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		// A malformed line is reported with the entries instead of failing the import.
+		var entry models.ImportedUser
+		err := entry.UnmarshalJSON(line)
+		if err != nil {
+			entry = models.ImportedUser{Malformed: fmt.Errorf("line %v: %w", n+1, apperror.ErrInvalidBody)}
+		}
+		entry.Line = n + 1
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
 }
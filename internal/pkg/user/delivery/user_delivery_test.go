@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+func TestParseImport(t *testing.T) {
+	email := "bob@example.com"
+
+	got, err := parseImport([]byte(` [{"nickname": "bob", "email": "bob@example.com"}, {"nickname": "alice", "password": "secret"}]`))
+	if err != nil {
+		t.Fatalf("array: parseImport() error = %v", err)
+	}
+	want := []models.ImportedUser{
+		{Nickname: "bob", Email: &email},
+		{Nickname: "alice", Password: "secret"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("array: parseImport() = %+v, want %+v", got, want)
+	}
+
+	got, err = parseImport([]byte("{\"nickname\": \"bob\", \"email\": \"bob@example.com\"}\r\n\n{\"nickname\": \"alice\", \"password\": \"secret\"}\n"))
+	if err != nil {
+		t.Fatalf("ndjson: parseImport() error = %v", err)
+	}
+	want = []models.ImportedUser{
+		{Nickname: "bob", Email: &email, Line: 1},
+		{Nickname: "alice", Password: "secret", Line: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ndjson: parseImport() = %+v, want %+v", got, want)
+	}
+
+	got, err = parseImport([]byte(" \n"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("empty body: parseImport() = %+v, %v, want no entries", got, err)
+	}
+
+	_, err = parseImport([]byte(`[{"nickname": "bob"`))
+	if !errors.Is(err, apperror.ErrInvalidBody) {
+		t.Errorf("malformed array: parseImport() error = %v, want %v", err, apperror.ErrInvalidBody)
+	}
+
+	// A malformed line becomes an entry of its own and the lines after it are still read.
+	got, err = parseImport([]byte("{\"nickname\": \"bob\"}\n{\"nickname\": \n{\"nickname\": \"alice\"}"))
+	if err != nil {
+		t.Fatalf("malformed line: parseImport() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("malformed line: parseImport() = %+v, want 3 entries", got)
+	}
+	if !errors.Is(got[1].Malformed, apperror.ErrInvalidBody) || got[1].Line != 2 {
+		t.Errorf("malformed line: entry = %+v, want %v on line 2", got[1], apperror.ErrInvalidBody)
+	}
+	if got[0].Malformed != nil || got[2].Malformed != nil || got[2].Nickname != "alice" || got[2].Line != 3 {
+		t.Errorf("malformed line: neighbours = %+v and %+v, want bob and alice read", got[0], got[2])
+	}
+}
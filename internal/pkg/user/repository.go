@@ -1,22 +1,74 @@
 package user
 
 import (
-	"fmt"
+	"context"
+	"time"
 
+	"github.com/aanufriev/forum/internal/pkg/apperror"
 	"github.com/aanufriev/forum/internal/pkg/models"
 )
 
 var (
-	ErrUserDoesntExists = fmt.Errorf("user exists")
-	ErrDataConflict     = fmt.Errorf("data conflict")
+	ErrUserDoesntExists = apperror.New(apperror.ErrNotFound, "user_not_found", "user doesn't exist")
+	ErrDataConflict     = apperror.New(apperror.ErrConflict, "user_conflict", "user data conflicts with another user")
+	ErrUserOwnsForums   = apperror.New(apperror.ErrConflict, "user_owns_forums", "user owns forums, transfer or delete them first")
+	ErrInvalidNickname  = apperror.New(apperror.ErrValidation, "invalid_nickname", "nickname must be non-empty and can't contain '/' or spaces")
+	ErrEmailRequired    = apperror.New(apperror.ErrValidation, "email_required", "email is required")
+	ErrReservedNickname = apperror.New(apperror.ErrValidation, "reserved_nickname", "nicknames starting with '"+TombstonePrefix+"' are reserved for deleted users")
+
+	ErrInvalidVerification  = apperror.New(apperror.ErrValidation, "invalid_verification_token", "verification token is invalid or expired")
+	ErrAlreadyVerified      = apperror.New(apperror.ErrConflict, "already_verified", "email address is already verified")
+	ErrVerificationDisabled = apperror.New(apperror.ErrNotFound, "verification_disabled", "email verification is turned off")
+
+	ErrIgnoreSelf = apperror.New(apperror.ErrValidation, "ignore_self", "users can't ignore themselves")
 )
 
+// TombstonePrefix starts the nicknames of anonymized users, followed by their id.
+// Nobody else may take such a nickname, or the user with that id couldn't be anonymized.
+const TombstonePrefix = "deleted."
+
+// Ways SearchParams.Query is matched against nicknames and full names.
+const (
+	MatchPrefix = "prefix"
+	MatchFuzzy  = "fuzzy"
+)
+
+// SearchParams describe one page of GET /api/users, ordered by nickname.
+type SearchParams struct {
+	Query string
+	Match string
+	// Forum limits the search to users who posted or created threads there.
+	Forum string
+	// Since is the last nickname of the previous page.
+	Since string
+	Limit int
+	Desc  bool
+}
+
 type Repository interface {
-	Create(user models.User) error
-	Get(nickname string) (models.User, error)
-	GetUsersWithNicknameAndEmail(nickname, email string) ([]models.User, error)
-	Update(user models.User) (models.User, error)
-	CheckIfUserExists(nickname string) (string, error)
-	GetUserNicknameWithEmail(email string) (string, error)
-	GetUserIDByNickname(nickname string) (int, error)
+	Create(ctx context.Context, user models.User) error
+	Get(ctx context.Context, nickname string) (models.User, error)
+	GetUsersWithNicknameAndEmail(ctx context.Context, nickname, email string) ([]models.User, error)
+	Update(ctx context.Context, user models.User) (models.User, error)
+	CheckIfUserExists(ctx context.Context, nickname string) (string, error)
+	GetUserNicknameWithEmail(ctx context.Context, email string) (string, error)
+	GetUserIDByNickname(ctx context.Context, nickname string) (int, error)
+	Delete(ctx context.Context, nickname string) error
+	Anonymize(ctx context.Context, nickname string) (models.User, error)
+	Rename(ctx context.Context, from string, to string) (models.User, error)
+	// GetRenamed returns the current nickname of whoever gave up oldNickname after since.
+	GetRenamed(ctx context.Context, oldNickname string, since time.Time) (string, error)
+	Search(ctx context.Context, params SearchParams) ([]models.User, error)
+	// RecomputeReputation rebuilds every reputation from thread_vote and returns how many were wrong.
+	RecomputeReputation(ctx context.Context) (int64, error)
+	// CreateVerification replaces the user's pending verifications with one for email.
+	CreateVerification(ctx context.Context, nickname string, email string, tokenHash []byte, expires time.Time) error
+	// ConfirmEmail makes the address of the token the user's verified email.
+	ConfirmEmail(ctx context.Context, nickname string, tokenHash []byte) (models.User, error)
+	// Ignore and Unignore do nothing if there is nothing to change.
+	Ignore(ctx context.Context, nickname string, other string) error
+	Unignore(ctx context.Context, nickname string, other string) error
+	// Import inserts users in one transaction, skipping those that clash with existing ones.
+	// Every user needs an email. The results line up with users and are either created or conflicts.
+	Import(ctx context.Context, users []models.User) ([]models.ImportResult, error)
 }
@@ -1,47 +1,226 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/metrics"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/statements"
 	"github.com/aanufriev/forum/internal/pkg/user"
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts userStatements
 }
 
-func New(db *sql.DB) user.Repository {
-	return UserRepository{
-		db: db,
+type userStatements struct {
+	create                       *sql.Stmt
+	get                          *sql.Stmt
+	getUsersWithNicknameAndEmail *sql.Stmt
+	update                       *sql.Stmt
+	getNickname                  *sql.Stmt
+	getNicknameByEmail           *sql.Stmt
+	getID                        *sql.Stmt
+	lock                         *sql.Stmt
+	ownsForums                   *sql.Stmt
+	affectedForums               *sql.Stmt
+	votedThreads                 *sql.Stmt
+	deletePosts                  *sql.Stmt
+	deleteVotes                  *sql.Stmt
+	deleteThreads                *sql.Stmt
+	recountVotes                 *sql.Stmt
+	pruneForumUsers              *sql.Stmt
+	recountForums                *sql.Stmt
+	delete                       *sql.Stmt
+	rename                       *sql.Stmt
+	renamePosts                  *sql.Stmt
+	anonymize                    *sql.Stmt
+	deleteSessions               *sql.Stmt
+	getByID                      *sql.Stmt
+	recordRename                 *sql.Stmt
+	forgetNicknames              *sql.Stmt
+	releaseNickname              *sql.Stmt
+	getRenamed                   *sql.Stmt
+	searchPrefix                 statements.Ordered
+	searchFuzzy                  statements.Ordered
+	recomputeReputation          *sql.Stmt
+	createVerification           *sql.Stmt
+	findVerification             *sql.Stmt
+	confirmEmail                 *sql.Stmt
+	forgetVerifications          *sql.Stmt
+	forgetModeration             *sql.Stmt
+	forgetMemberships            *sql.Stmt
+	forgetIgnores                *sql.Stmt
+	ignore                       *sql.Stmt
+	unignore                     *sql.Stmt
+	importUsers                  *sql.Stmt
+	importConflicts              *sql.Stmt
+}
+
+// The search filters shared by both match modes. $2 (forum) and $3 (since) may be NULL.
+const (
+	selectSearch = "SELECT u.nickname, u.fullname, u.email, u.about, u.reputation, u.verified FROM users AS u"
+	searchFilter = `
+	AND ($2::citext IS NULL OR EXISTS (SELECT 1 FROM forum_user AS fu WHERE fu.forum_slug = $2 AND fu.nickname = u.nickname))`
+	prefixMatch = `
+	WHERE (lower(u.nickname::text) LIKE $1 OR lower(u.fullname::text) LIKE $1 OR lower(u.fullname::text) LIKE '% ' || $1)`
+	fuzzyMatch = `
+	WHERE (lower(u.nickname::text) % $1 OR lower(u.fullname::text) % $1)`
+)
+
+// New prepares the repository's statements, so the schema must already be migrated.
+func New(ctx context.Context, db *sql.DB) (user.Repository, error) {
+	var s userStatements
+	err := statements.Prepare(
+		ctx, db,
+		statements.Statement{Dest: &s.create, Query: "INSERT INTO users (nickname, fullname, email, about, password_hash) VALUES ($1, $2, $3, $4, $5)"},
+		statements.Statement{Dest: &s.get, Query: `SELECT id, nickname, fullname, email, about, reputation, verified FROM users
+		WHERE nickname = $1`},
+		statements.Statement{Dest: &s.getUsersWithNicknameAndEmail, Query: `SELECT nickname, fullname, email, about, reputation, verified FROM users
+		WHERE nickname = $1 OR email = $2`},
+		statements.Statement{Dest: &s.update, Query: `UPDATE users SET fullname = $1, email = $2, about = $3, verified = verified AND email = $2
+		WHERE id = $4`},
+		statements.Statement{Dest: &s.getNickname, Query: "SELECT nickname FROM users WHERE nickname = $1"},
+		statements.Statement{Dest: &s.getNicknameByEmail, Query: "SELECT nickname FROM users WHERE email = $1"},
+		statements.Statement{Dest: &s.getID, Query: "SELECT id FROM users WHERE nickname = $1"},
+
+		statements.Statement{Dest: &s.lock, Query: "SELECT id, nickname FROM users WHERE nickname = $1 FOR UPDATE"},
+		statements.Statement{Dest: &s.ownsForums, Query: "SELECT EXISTS(SELECT 1 FROM forums WHERE user_nickname = $1)"},
+		statements.Statement{Dest: &s.affectedForums, Query: `SELECT ARRAY(
+			SELECT forum FROM threads WHERE author = $1
+			UNION
+			SELECT forum FROM posts WHERE author = $1
+		)`},
+		statements.Statement{Dest: &s.votedThreads, Query: "SELECT ARRAY(SELECT thread_id FROM thread_vote WHERE nickname = $1)"},
+		statements.Statement{Dest: &s.deletePosts, Query: `DELETE FROM posts
+		WHERE thread IN (SELECT id FROM threads WHERE author = $1)
+		OR path && ARRAY(SELECT id FROM posts WHERE author = $1)`},
+		statements.Statement{Dest: &s.deleteVotes, Query: "DELETE FROM thread_vote WHERE nickname = $1"},
+		statements.Statement{Dest: &s.deleteThreads, Query: "DELETE FROM threads WHERE author = $1"},
+		statements.Statement{Dest: &s.recountVotes, Query: `UPDATE threads AS t
+		SET votes = COALESCE((SELECT sum(vote) FROM thread_vote WHERE thread_id = t.id), 0)
+		WHERE t.id = ANY($1)`},
+		statements.Statement{Dest: &s.pruneForumUsers, Query: `DELETE FROM forum_user AS fu
+		WHERE fu.forum_slug = ANY($1::citext[])
+		AND NOT EXISTS (SELECT 1 FROM threads WHERE forum = fu.forum_slug AND author = fu.nickname)
+		AND NOT EXISTS (SELECT 1 FROM posts WHERE forum = fu.forum_slug AND author = fu.nickname)`},
+		statements.Statement{Dest: &s.recountForums, Query: `UPDATE forums AS f
+		SET thread_count = (SELECT count(*) FROM threads WHERE forum = f.slug),
+		post_count = (SELECT count(*) FROM posts WHERE forum = f.slug)
+		WHERE f.slug = ANY($1::citext[])`},
+		statements.Statement{Dest: &s.delete, Query: "DELETE FROM users WHERE id = $1"},
+
+		statements.Statement{Dest: &s.rename, Query: "UPDATE users SET nickname = $2 WHERE id = $1"},
+		statements.Statement{Dest: &s.renamePosts, Query: "UPDATE posts SET author = $2 WHERE author = $1"},
+		statements.Statement{Dest: &s.deleteSessions, Query: "DELETE FROM sessions WHERE user_id = $1"},
+
+		statements.Statement{Dest: &s.getByID, Query: "SELECT nickname, fullname, email, about, reputation, verified FROM users WHERE id = $1"},
+		statements.Statement{Dest: &s.recordRename, Query: `INSERT INTO nickname_history (old_nickname, user_id) VALUES ($1, $2)
+		ON CONFLICT (old_nickname) DO UPDATE SET user_id = excluded.user_id, renamed_at = excluded.renamed_at`},
+		statements.Statement{Dest: &s.forgetNicknames, Query: "DELETE FROM nickname_history WHERE user_id = $1"},
+		statements.Statement{Dest: &s.releaseNickname, Query: "DELETE FROM nickname_history WHERE old_nickname = $1"},
+		statements.Statement{Dest: &s.getRenamed, Query: `SELECT u.nickname FROM nickname_history AS h
+		JOIN users AS u ON u.id = h.user_id
+		WHERE h.old_nickname = $1 AND h.renamed_at > $2`},
+
+		statements.Statement{Dest: &s.searchPrefix.Asc, Query: selectSearch + prefixMatch + searchFilter + `
+		AND ($3::citext IS NULL OR u.nickname > $3) ORDER BY u.nickname ASC LIMIT $4`},
+		statements.Statement{Dest: &s.searchPrefix.Desc, Query: selectSearch + prefixMatch + searchFilter + `
+		AND ($3::citext IS NULL OR u.nickname < $3) ORDER BY u.nickname DESC LIMIT $4`},
+		statements.Statement{Dest: &s.searchFuzzy.Asc, Query: selectSearch + fuzzyMatch + searchFilter + `
+		AND ($3::citext IS NULL OR u.nickname > $3) ORDER BY u.nickname ASC LIMIT $4`},
+		statements.Statement{Dest: &s.searchFuzzy.Desc, Query: selectSearch + fuzzyMatch + searchFilter + `
+		AND ($3::citext IS NULL OR u.nickname < $3) ORDER BY u.nickname DESC LIMIT $4`},
+		statements.Statement{Dest: &s.recomputeReputation, Query: `UPDATE users AS u SET reputation = r.score
+		FROM (
+			SELECT u.id, COALESCE(sum(v.vote), 0) AS score FROM users AS u
+			LEFT JOIN threads AS t ON t.author = u.nickname
+			LEFT JOIN thread_vote AS v ON v.thread_id = t.id
+			GROUP BY u.id
+		) AS r
+		WHERE u.id = r.id AND u.reputation <> r.score`},
+		statements.Statement{Dest: &s.createVerification, Query: `WITH u AS (SELECT id FROM users WHERE nickname = $1),
+		stale AS (DELETE FROM email_verifications WHERE user_id = (SELECT id FROM u) OR expires <= now())
+		INSERT INTO email_verifications (token_hash, user_id, email, expires)
+		SELECT $3, id, $2, $4 FROM u`},
+		statements.Statement{Dest: &s.findVerification, Query: `SELECT v.user_id, v.email FROM email_verifications AS v
+		JOIN users AS u ON u.id = v.user_id
+		WHERE v.token_hash = $1 AND u.nickname = $2 AND v.expires > now()
+		FOR UPDATE OF v`},
+		statements.Statement{Dest: &s.confirmEmail, Query: `UPDATE users SET email = $2, verified = true
+		WHERE id = $1
+		RETURNING nickname, fullname, email, about, reputation, verified`},
+		statements.Statement{Dest: &s.forgetVerifications, Query: "DELETE FROM email_verifications WHERE user_id = $1"},
+		statements.Statement{Dest: &s.forgetModeration, Query: "DELETE FROM forum_moderators WHERE user_id = $1"},
+		statements.Statement{Dest: &s.forgetMemberships, Query: "DELETE FROM forum_members WHERE user_id = $1"},
+		statements.Statement{Dest: &s.forgetIgnores, Query: "DELETE FROM user_ignore WHERE user_id = $1 OR ignored_id = $1"},
+		statements.Statement{Dest: &s.ignore, Query: `INSERT INTO user_ignore (user_id, ignored_id)
+		SELECT u.id, o.id FROM users AS u, users AS o
+		WHERE u.nickname = $1 AND o.nickname = $2
+		ON CONFLICT DO NOTHING`},
+		statements.Statement{Dest: &s.unignore, Query: `DELETE FROM user_ignore AS ig
+		USING users AS u, users AS o
+		WHERE ig.user_id = u.id AND ig.ignored_id = o.id AND u.nickname = $1 AND o.nickname = $2`},
+		statements.Statement{Dest: &s.importUsers, Query: `INSERT INTO users (nickname, fullname, email, about, password_hash)
+		SELECT u.nickname, COALESCE(u.fullname, ''), u.email, COALESCE(u.about, ''), u.password_hash
+		FROM unnest($1::citext[], $2::citext[], $3::citext[], $4::text[], $5::text[])
+		WITH ORDINALITY AS u(nickname, fullname, email, about, password_hash, n)
+		ORDER BY u.n
+		ON CONFLICT DO NOTHING
+		RETURNING nickname, email`},
+		statements.Statement{Dest: &s.importConflicts, Query: `SELECT nickname, fullname, email, about, reputation, verified FROM users
+		WHERE nickname = ANY($1::citext[]) OR email = ANY($2::citext[])`},
+		statements.Statement{Dest: &s.anonymize, Query: `UPDATE users SET email = $2, fullname = $3, about = '', password_hash = NULL, verified = false, role = 'member'
+		WHERE id = $1
+		RETURNING nickname, fullname, email, about, reputation, verified`},
+	)
+	if err != nil {
+		return nil, err
 	}
+
+	return UserRepository{
+		db:    db,
+		stmts: s,
+	}, nil
 }
 
-func (u UserRepository) Create(model models.User) error {
-	_, err := u.db.Exec(
-		"INSERT INTO users (nickname, fullname, email, about) VALUES ($1, $2, $3, $4)",
-		model.Nickname, model.Fullname, model.Email, model.About,
+func (u UserRepository) Create(ctx context.Context, model models.User) error {
+	defer metrics.ObserveQuery("user", "Create", time.Now())
+
+	_, err := u.stmts.create.ExecContext(
+		ctx,
+		model.Nickname, model.Fullname, model.Email, model.About, model.PasswordHash,
 	)
 
 	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return fmt.Errorf("couldn't create user '%v': %w", model.Nickname, user.ErrDataConflict)
+		}
 		return fmt.Errorf("couldn't insert user: %v. Error: %w", model, err)
 	}
 
 	return nil
 }
 
-func (u UserRepository) Get(nickname string) (models.User, error) {
+func (u UserRepository) Get(ctx context.Context, nickname string) (models.User, error) {
+	defer metrics.ObserveQuery("user", "Get", time.Now())
+
 	var model models.User
-	err := u.db.QueryRow(
-		`SELECT id, nickname, fullname, email, about FROM users
-		WHERE nickname = $1`,
+	err := u.stmts.get.QueryRowContext(
+		ctx,
 		nickname,
-	).Scan(&model.ID, &model.Nickname, &model.Fullname, &model.Email, &model.About)
+	).Scan(&model.ID, &model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return models.User{}, user.ErrUserDoesntExists
+			return models.User{}, fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
 		}
 		return models.User{}, fmt.Errorf("couldn't get user with nickname '%v'. Error: %w", nickname, err)
 	}
@@ -49,10 +228,11 @@ func (u UserRepository) Get(nickname string) (models.User, error) {
 	return model, nil
 }
 
-func (u UserRepository) GetUsersWithNicknameAndEmail(nickname, email string) ([]models.User, error) {
-	rows, err := u.db.Query(
-		`SELECT nickname, fullname, email, about FROM users
-		WHERE nickname = $1 OR email = $2`,
+func (u UserRepository) GetUsersWithNicknameAndEmail(ctx context.Context, nickname, email string) ([]models.User, error) {
+	defer metrics.ObserveQuery("user", "GetUsersWithNicknameAndEmail", time.Now())
+
+	rows, err := u.stmts.getUsersWithNicknameAndEmail.QueryContext(
+		ctx,
 		nickname, email,
 	)
 	if err != nil {
@@ -63,7 +243,7 @@ func (u UserRepository) GetUsersWithNicknameAndEmail(nickname, email string) ([]
 	users := make([]models.User, 0, 2)
 	user := models.User{}
 	for rows.Next() {
-		err = rows.Scan(&user.Nickname, &user.Fullname, &user.Email, &user.About)
+		err = rows.Scan(&user.Nickname, &user.Fullname, &user.Email, &user.About, &user.Reputation, &user.Verified)
 		if err != nil {
 			return nil, fmt.Errorf(`couldn't get users with nickname '%v' and email '%v'. Error: %w`, nickname, email, err)
 		}
@@ -74,8 +254,10 @@ func (u UserRepository) GetUsersWithNicknameAndEmail(nickname, email string) ([]
 	return users, nil
 }
 
-func (u UserRepository) Update(model models.User) (models.User, error) {
-	userFromDB, err := u.Get(model.Nickname)
+func (u UserRepository) Update(ctx context.Context, model models.User) (models.User, error) {
+	defer metrics.ObserveQuery("user", "Update", time.Now())
+
+	userFromDB, err := u.Get(ctx, model.Nickname)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -91,57 +273,576 @@ func (u UserRepository) Update(model models.User) (models.User, error) {
 	if model.About == nil {
 		model.About = userFromDB.About
 	}
+	model.Reputation = userFromDB.Reputation
+	model.Verified = userFromDB.Verified && strings.EqualFold(*model.Email, *userFromDB.Email)
 
-	_, err = u.db.Exec(
-		`UPDATE users SET fullname = $1, email = $2, about = $3
-		WHERE id = $4`,
+	_, err = u.stmts.update.ExecContext(
+		ctx,
 		model.Fullname, model.Email, model.About, userFromDB.ID,
 	)
 
 	if err != nil {
-		return models.User{}, user.ErrDataConflict
+		if apperror.IsUniqueViolation(err) {
+			return models.User{}, fmt.Errorf("couldn't update user '%v': %w", model.Nickname, user.ErrDataConflict)
+		}
+		return models.User{}, fmt.Errorf("couldn't update user '%v'. Error: %w", model.Nickname, err)
 	}
 
 	return model, nil
 }
 
-func (u UserRepository) CheckIfUserExists(nickname string) (string, error) {
-	err := u.db.QueryRow(
-		"SELECT nickname FROM users WHERE nickname = $1",
+func (u UserRepository) CheckIfUserExists(ctx context.Context, nickname string) (string, error) {
+	defer metrics.ObserveQuery("user", "CheckIfUserExists", time.Now())
+
+	err := u.stmts.getNickname.QueryRowContext(
+		ctx,
 		nickname,
 	).Scan(&nickname)
 
 	if err != nil {
-		return "", fmt.Errorf("user doesnt exist: %w", err)
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't check user with nickname '%v'. Error: %w", nickname, err)
 	}
 
 	return nickname, nil
 }
 
-func (u UserRepository) GetUserNicknameWithEmail(email string) (string, error) {
+func (u UserRepository) GetUserNicknameWithEmail(ctx context.Context, email string) (string, error) {
+	defer metrics.ObserveQuery("user", "GetUserNicknameWithEmail", time.Now())
+
 	var nickname string
-	err := u.db.QueryRow(
-		"SELECT nickname FROM users WHERE email = $1",
+	err := u.stmts.getNicknameByEmail.QueryRowContext(
+		ctx,
 		email,
 	).Scan(&nickname)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find user with email '%v': %w", email, user.ErrUserDoesntExists)
+		}
 		return "", fmt.Errorf(`couldn't get user nickname with email '%v'. Error: %w`, email, err)
 	}
 
 	return nickname, nil
 }
 
-func (u UserRepository) GetUserIDByNickname(nickname string) (int, error) {
+func (u UserRepository) GetUserIDByNickname(ctx context.Context, nickname string) (int, error) {
+	defer metrics.ObserveQuery("user", "GetUserIDByNickname", time.Now())
+
 	var id int
-	err := u.db.QueryRow(
-		"SELECT id FROM users WHERE nickname = $1",
+	err := u.stmts.getID.QueryRowContext(
+		ctx,
 		nickname,
 	).Scan(&id)
 
 	if err != nil {
-		return 0, err
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+		}
+		return 0, fmt.Errorf("couldn't get id of user '%v'. Error: %w", nickname, err)
 	}
 
 	return id, nil
 }
+
+// Tombstone identity given to anonymized users. The nickname keeps the user id,
+// so it stays unique and the user's content stays attributed to one account.
+const (
+	tombstoneNickname = user.TombstonePrefix + "%d"
+	tombstoneEmail    = user.TombstonePrefix + "%d@users.invalid"
+	tombstoneFullname = "Deleted user"
+)
+
+// lock finds the user and locks their row until tx ends.
+func (u UserRepository) lock(ctx context.Context, tx *sql.Tx, nickname string) (int, string, error) {
+	var id int
+	err := tx.StmtContext(ctx, u.stmts.lock).QueryRowContext(
+		ctx,
+		nickname,
+	).Scan(&id, &nickname)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+		}
+		return 0, "", fmt.Errorf("couldn't lock user '%v'. Error: %w", nickname, err)
+	}
+
+	return id, nickname, nil
+}
+
+// rename changes the nickname of user id. Foreign keys follow the users row
+// through ON UPDATE CASCADE; posts.author has none and is updated here.
+func (u UserRepository) rename(ctx context.Context, tx *sql.Tx, id int, from, to string) error {
+	_, err := tx.StmtContext(ctx, u.stmts.rename).ExecContext(ctx, id, to)
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return fmt.Errorf("couldn't rename user '%v' to '%v': %w", from, to, user.ErrDataConflict)
+		}
+		return fmt.Errorf("couldn't rename user '%v'. Error: %w", from, err)
+	}
+
+	_, err = tx.StmtContext(ctx, u.stmts.renamePosts).ExecContext(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("couldn't move posts of '%v' to '%v'. Error: %w", from, to, err)
+	}
+
+	return nil
+}
+
+// Delete removes the user together with their threads, their posts and the replies
+// below them, and their votes, then recomputes the counters those rows contributed to.
+// Users who own forums can't be deleted.
+func (u UserRepository) Delete(ctx context.Context, nickname string) error {
+	defer metrics.ObserveQuery("user", "Delete", time.Now())
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	id, nickname, err := u.lock(ctx, tx, nickname)
+	if err != nil {
+		return err
+	}
+
+	var ownsForums bool
+	err = tx.StmtContext(ctx, u.stmts.ownsForums).QueryRowContext(ctx, nickname).Scan(&ownsForums)
+	if err != nil {
+		return fmt.Errorf("couldn't check forums of user '%v'. Error: %w", nickname, err)
+	}
+
+	if ownsForums {
+		return fmt.Errorf("can't delete user '%v': %w", nickname, user.ErrUserOwnsForums)
+	}
+
+	var (
+		forums  pq.StringArray
+		threads pq.Int64Array
+	)
+
+	err = tx.StmtContext(ctx, u.stmts.affectedForums).QueryRowContext(ctx, nickname).Scan(&forums)
+	if err != nil {
+		return fmt.Errorf("couldn't get forums of user '%v'. Error: %w", nickname, err)
+	}
+
+	err = tx.StmtContext(ctx, u.stmts.votedThreads).QueryRowContext(ctx, nickname).Scan(&threads)
+	if err != nil {
+		return fmt.Errorf("couldn't get votes of user '%v'. Error: %w", nickname, err)
+	}
+
+	for _, step := range []struct {
+		stmt *sql.Stmt
+		arg  interface{}
+	}{
+		{u.stmts.deletePosts, nickname},
+		{u.stmts.deleteVotes, nickname},
+		{u.stmts.deleteThreads, nickname},
+		{u.stmts.recountVotes, threads},
+		{u.stmts.pruneForumUsers, forums},
+		{u.stmts.recountForums, forums},
+		{u.stmts.delete, id},
+	} {
+		_, err = tx.StmtContext(ctx, step.stmt).ExecContext(ctx, step.arg)
+		if err != nil {
+			return fmt.Errorf("couldn't delete user '%v'. Error: %w", nickname, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Anonymize replaces the user's identity with a tombstone and signs them out everywhere.
+// Their threads, posts and votes stay where they are and are attributed to the tombstone nickname.
+// Roles, moderation, forum memberships and ignore lists are not kept.
+func (u UserRepository) Anonymize(ctx context.Context, nickname string) (models.User, error) {
+	defer metrics.ObserveQuery("user", "Anonymize", time.Now())
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	id, nickname, err := u.lock(ctx, tx, nickname)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	_, err = tx.StmtContext(ctx, u.stmts.deleteSessions).ExecContext(ctx, id)
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't delete sessions of user '%v'. Error: %w", nickname, err)
+	}
+
+	_, err = tx.StmtContext(ctx, u.stmts.forgetVerifications).ExecContext(ctx, id)
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't delete email verifications of user '%v'. Error: %w", nickname, err)
+	}
+
+	// The tombstone inherits no moderation, memberships or ignore lists; the update below resets its role.
+	for _, stmt := range []*sql.Stmt{u.stmts.forgetModeration, u.stmts.forgetMemberships, u.stmts.forgetIgnores} {
+		_, err = tx.StmtContext(ctx, stmt).ExecContext(ctx, id)
+		if err != nil {
+			return models.User{}, fmt.Errorf("couldn't drop privileges of user '%v'. Error: %w", nickname, err)
+		}
+	}
+
+	tombstone := fmt.Sprintf(tombstoneNickname, id)
+
+	// Former names must not lead to the tombstone.
+	_, err = tx.StmtContext(ctx, u.stmts.forgetNicknames).ExecContext(ctx, id)
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't forget nicknames of user '%v'. Error: %w", nickname, err)
+	}
+
+	if nickname != tombstone {
+		err = u.rename(ctx, tx, id, nickname, tombstone)
+		if err != nil {
+			return models.User{}, err
+		}
+	}
+
+	var model models.User
+	err = tx.StmtContext(ctx, u.stmts.anonymize).QueryRowContext(
+		ctx,
+		id, fmt.Sprintf(tombstoneEmail, id), tombstoneFullname,
+	).Scan(&model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
+
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return models.User{}, fmt.Errorf("couldn't anonymize user '%v': %w", nickname, user.ErrDataConflict)
+		}
+		return models.User{}, fmt.Errorf("couldn't anonymize user '%v'. Error: %w", nickname, err)
+	}
+
+	return model, tx.Commit()
+}
+
+// Rename moves the user and everything they wrote to a new nickname in one transaction
+// and remembers the old one. Whoever gave up the new nickname before loses its redirect.
+func (u UserRepository) Rename(ctx context.Context, from string, to string) (models.User, error) {
+	defer metrics.ObserveQuery("user", "Rename", time.Now())
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	id, from, err := u.lock(ctx, tx, from)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = u.rename(ctx, tx, id, from, to)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	_, err = tx.StmtContext(ctx, u.stmts.releaseNickname).ExecContext(ctx, to)
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't release nickname '%v'. Error: %w", to, err)
+	}
+
+	if !strings.EqualFold(from, to) {
+		_, err = tx.StmtContext(ctx, u.stmts.recordRename).ExecContext(ctx, from, id)
+		if err != nil {
+			return models.User{}, fmt.Errorf("couldn't record old nickname '%v'. Error: %w", from, err)
+		}
+	}
+
+	var model models.User
+	err = tx.StmtContext(ctx, u.stmts.getByID).QueryRowContext(
+		ctx,
+		id,
+	).Scan(&model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't get renamed user '%v'. Error: %w", to, err)
+	}
+
+	return model, tx.Commit()
+}
+
+func (u UserRepository) GetRenamed(ctx context.Context, oldNickname string, since time.Time) (string, error) {
+	defer metrics.ObserveQuery("user", "GetRenamed", time.Now())
+
+	var nickname string
+	err := u.stmts.getRenamed.QueryRowContext(
+		ctx,
+		oldNickname, since,
+	).Scan(&nickname)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("can't find user with nickname '%v': %w", oldNickname, user.ErrUserDoesntExists)
+		}
+		return "", fmt.Errorf("couldn't look up old nickname '%v'. Error: %w", oldNickname, err)
+	}
+
+	return nickname, nil
+}
+
+func (u UserRepository) Search(ctx context.Context, params user.SearchParams) ([]models.User, error) {
+	defer metrics.ObserveQuery("user", "Search", time.Now())
+
+	stmt := u.stmts.searchPrefix.Pick(params.Desc)
+	pattern := statements.EscapeLike(strings.ToLower(params.Query)) + "%"
+	if params.Match == user.MatchFuzzy {
+		stmt = u.stmts.searchFuzzy.Pick(params.Desc)
+		pattern = strings.ToLower(params.Query)
+	}
+
+	rows, err := stmt.QueryContext(
+		ctx,
+		pattern, statements.Nullable(params.Forum), statements.Nullable(params.Since), params.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't search users matching '%v'. Error: %w", params.Query, err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, params.Limit)
+	for rows.Next() {
+		var model models.User
+		err = rows.Scan(&model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't search users matching '%v'. Error: %w", params.Query, err)
+		}
+
+		users = append(users, model)
+	}
+
+	return users, rows.Err()
+}
+
+func (u UserRepository) RecomputeReputation(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("user", "RecomputeReputation", time.Now())
+
+	result, err := u.stmts.recomputeReputation.ExecContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't recompute reputation. Error: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (u UserRepository) CreateVerification(ctx context.Context, nickname string, email string, tokenHash []byte, expires time.Time) error {
+	defer metrics.ObserveQuery("user", "CreateVerification", time.Now())
+
+	result, err := u.stmts.createVerification.ExecContext(
+		ctx,
+		nickname, email, tokenHash, expires,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't create email verification for '%v'. Error: %w", nickname, err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if created == 0 {
+		return fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+	}
+
+	return nil
+}
+
+// ConfirmEmail also drops the user's other pending verifications, they are stale now.
+func (u UserRepository) ConfirmEmail(ctx context.Context, nickname string, tokenHash []byte) (models.User, error) {
+	defer metrics.ObserveQuery("user", "ConfirmEmail", time.Now())
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var (
+		id    int
+		email string
+	)
+	err = tx.StmtContext(ctx, u.stmts.findVerification).QueryRowContext(
+		ctx,
+		tokenHash, nickname,
+	).Scan(&id, &email)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.User{}, fmt.Errorf("can't confirm email of '%v': %w", nickname, user.ErrInvalidVerification)
+		}
+		return models.User{}, fmt.Errorf("couldn't find email verification of '%v'. Error: %w", nickname, err)
+	}
+
+	var model models.User
+	err = tx.StmtContext(ctx, u.stmts.confirmEmail).QueryRowContext(
+		ctx,
+		id, email,
+	).Scan(&model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
+
+	if err != nil {
+		if apperror.IsUniqueViolation(err) {
+			return models.User{}, fmt.Errorf("email '%v' was taken before '%v' confirmed it: %w", email, nickname, user.ErrDataConflict)
+		}
+		return models.User{}, fmt.Errorf("couldn't confirm email of '%v'. Error: %w", nickname, err)
+	}
+
+	_, err = tx.StmtContext(ctx, u.stmts.forgetVerifications).ExecContext(ctx, id)
+	if err != nil {
+		return models.User{}, fmt.Errorf("couldn't delete email verifications of '%v'. Error: %w", nickname, err)
+	}
+
+	return model, tx.Commit()
+}
+
+func (u UserRepository) Ignore(ctx context.Context, nickname string, other string) error {
+	defer metrics.ObserveQuery("user", "Ignore", time.Now())
+
+	_, err := u.stmts.ignore.ExecContext(ctx, nickname, other)
+	if err != nil {
+		return fmt.Errorf("couldn't make '%v' ignore '%v'. Error: %w", nickname, other, err)
+	}
+
+	return nil
+}
+
+func (u UserRepository) Unignore(ctx context.Context, nickname string, other string) error {
+	defer metrics.ObserveQuery("user", "Unignore", time.Now())
+
+	_, err := u.stmts.unignore.ExecContext(ctx, nickname, other)
+	if err != nil {
+		return fmt.Errorf("couldn't make '%v' stop ignoring '%v'. Error: %w", nickname, other, err)
+	}
+
+	return nil
+}
+
+func (u UserRepository) Import(ctx context.Context, users []models.User) ([]models.ImportResult, error) {
+	defer metrics.ObserveQuery("user", "Import", time.Now())
+
+	nicknames := make([]string, 0, len(users))
+	fullnames := make([]*string, 0, len(users))
+	emails := make([]*string, 0, len(users))
+	abouts := make([]*string, 0, len(users))
+	hashes := make([]*string, 0, len(users))
+	for _, model := range users {
+		nicknames = append(nicknames, model.Nickname)
+		fullnames = append(fullnames, model.Fullname)
+		emails = append(emails, model.Email)
+		abouts = append(abouts, model.About)
+		hashes = append(hashes, model.PasswordHash)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.StmtContext(ctx, u.stmts.importUsers).QueryContext(
+		ctx,
+		pq.Array(nicknames), pq.Array(fullnames), pq.Array(emails), pq.Array(abouts), pq.Array(hashes),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't import %v users. Error: %w", len(users), err)
+	}
+
+	inserted := make(map[string]int)
+	for rows.Next() {
+		var nickname, email string
+		err = rows.Scan(&nickname, &email)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("couldn't scan imported user. Error: %w", err)
+		}
+		inserted[importKey(nickname, email)]++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't import %v users. Error: %w", len(users), err)
+	}
+
+	results := make([]models.ImportResult, len(users))
+	var conflicted bool
+	for i, model := range users {
+		results[i] = models.ImportResult{Nickname: model.Nickname, Status: user.ImportConflict}
+
+		key := importKey(model.Nickname, *model.Email)
+		if inserted[key] > 0 {
+			inserted[key]--
+			results[i].Status = user.ImportCreated
+			continue
+		}
+		conflicted = true
+	}
+
+	if conflicted {
+		err = u.reportConflicts(ctx, tx, users, results, nicknames, emails)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, tx.Commit()
+}
+
+// importKey identifies an inserted row. Rows are inserted in order, so of
+// identical entries the first one got in.
+func importKey(nickname string, email string) string {
+	return strings.ToLower(nickname) + "\x00" + strings.ToLower(email)
+}
+
+// reportConflicts fills in the users each conflicting entry clashes with, including
+// ones created earlier in the same batch.
+func (u UserRepository) reportConflicts(ctx context.Context, tx *sql.Tx, users []models.User, results []models.ImportResult, nicknames []string, emails []*string) error {
+	rows, err := tx.StmtContext(ctx, u.stmts.importConflicts).QueryContext(
+		ctx,
+		pq.Array(nicknames), pq.Array(emails),
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't get users conflicting with the import. Error: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make([]models.User, 0)
+	for rows.Next() {
+		var model models.User
+		err = rows.Scan(&model.Nickname, &model.Fullname, &model.Email, &model.About, &model.Reputation, &model.Verified)
+		if err != nil {
+			return fmt.Errorf("couldn't scan conflicting user. Error: %w", err)
+		}
+		existing = append(existing, model)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("couldn't get users conflicting with the import. Error: %w", err)
+	}
+
+	for i, model := range users {
+		if results[i].Status != user.ImportConflict {
+			continue
+		}
+
+		for _, other := range existing {
+			if strings.EqualFold(other.Nickname, model.Nickname) || strings.EqualFold(*other.Email, *model.Email) {
+				results[i].Conflicts = append(results[i].Conflicts, other)
+			}
+		}
+	}
+
+	return nil
+}
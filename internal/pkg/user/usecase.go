@@ -1,13 +1,49 @@
 package user
 
-import "github.com/aanufriev/forum/internal/pkg/models"
+import (
+	"context"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+)
+
+// Modes of Usecase.Delete.
+const (
+	// DeleteModeHard removes the user with their threads, posts and votes.
+	DeleteModeHard = "hard"
+	// DeleteModeAnonymize replaces the user's identity with a tombstone and keeps their content.
+	DeleteModeAnonymize = "anonymize"
+)
+
+// Statuses of models.ImportResult.
+const (
+	ImportCreated  = "created"
+	ImportConflict = "conflict"
+	ImportInvalid  = "invalid"
+)
 
 type Usecase interface {
-	Create(user models.User) error
-	Get(nickname string) (models.User, error)
-	GetUsersWithNicknameAndEmail(nickname, email string) ([]models.User, error)
-	Update(model models.User) (models.User, error)
-	CheckIfUserExists(nickname string) (string, error)
-	GetUserNicknameWithEmail(email string) (string, error)
-	GetUserIDByNickname(nickname string) (int, error)
+	// Create hashes password into the new user's profile unless it is empty.
+	Create(ctx context.Context, user models.User, password string) error
+	Get(ctx context.Context, nickname string) (models.User, error)
+	GetUsersWithNicknameAndEmail(ctx context.Context, nickname, email string) ([]models.User, error)
+	Update(ctx context.Context, model models.User) (models.User, error)
+	CheckIfUserExists(ctx context.Context, nickname string) (string, error)
+	GetUserNicknameWithEmail(ctx context.Context, email string) (string, error)
+	GetUserIDByNickname(ctx context.Context, nickname string) (int, error)
+	// Delete is for the user themself and administrators.
+	Delete(ctx context.Context, nickname string, mode string) (models.User, error)
+	Rename(ctx context.Context, from string, to string) (models.User, error)
+	// ResolveRenamed returns the current nickname of a user who gave up nickname
+	// recently enough for old links to keep working.
+	ResolveRenamed(ctx context.Context, nickname string) (string, error)
+	Search(ctx context.Context, params SearchParams) ([]models.User, error)
+	RecomputeReputation(ctx context.Context) (int64, error)
+	ConfirmEmail(ctx context.Context, nickname string, token string) (models.User, error)
+	// ResendVerification mails a new token for the user's current, unverified email.
+	ResendVerification(ctx context.Context, nickname string) error
+	Ignore(ctx context.Context, nickname string, other string) error
+	Unignore(ctx context.Context, nickname string, other string) error
+	// Import creates users in batches, reporting on every entry instead of failing on the first bad one.
+	// Imported addresses start unverified, like registered ones, and get a token each.
+	Import(ctx context.Context, users []models.ImportedUser) (models.ImportReport, error)
 }
@@ -1,44 +1,480 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/apperror"
+	"github.com/aanufriev/forum/internal/pkg/auth"
+	"github.com/aanufriev/forum/internal/pkg/forum"
+	"github.com/aanufriev/forum/internal/pkg/mail"
 	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
 	"github.com/aanufriev/forum/internal/pkg/user"
+	"github.com/sirupsen/logrus"
+)
+
+// Page sizes of Search.
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
 )
 
+// importBatch is how many users Import inserts per transaction.
+const importBatch = 500
+
+const verificationSubject = "Confirm your email address"
+
+const verificationBody = `Hello %v,
+
+please confirm that %v is your email address by sending
+
+POST /api/user/%v/verify
+{"token": "%v"}
+
+The token is valid until %v. If you didn't ask for it, ignore this message.
+`
+
 type UserUsecase struct {
-	userRepository user.Repository
+	userRepository  user.Repository
+	redirectPeriod  time.Duration
+	mailer          mail.Mailer
+	verificationTTL time.Duration
+	permissions     permission.Usecase
 }
 
-func New(userRepository user.Repository) user.Usecase {
+// New builds the usecase. Old nicknames resolve to the new ones for redirectPeriod
+// after a rename; 0 turns that off. With a nil mailer email addresses are taken
+// as they are, otherwise they are confirmed by tokens valid for verificationTTL.
+func New(userRepository user.Repository, redirectPeriod time.Duration, mailer mail.Mailer, verificationTTL time.Duration, permissions permission.Usecase) user.Usecase {
 	return UserUsecase{
-		userRepository: userRepository,
+		userRepository:  userRepository,
+		redirectPeriod:  redirectPeriod,
+		mailer:          mailer,
+		verificationTTL: verificationTTL,
+		permissions:     permissions,
+	}
+}
+
+func (u UserUsecase) Create(ctx context.Context, model models.User, password string) error {
+	if reservedNickname(model.Nickname) {
+		return fmt.Errorf("can't create '%v': %w", model.Nickname, user.ErrReservedNickname)
+	}
+
+	if password != "" {
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		model.PasswordHash = &hash
+	}
+
+	err := u.userRepository.Create(ctx, model)
+	if err != nil || u.mailer == nil {
+		return err
+	}
+
+	// The account exists now, a lost message can be sent again with ResendVerification.
+	err = u.sendVerification(ctx, model.Nickname, *model.Email)
+	if err != nil {
+		logrus.WithError(err).WithField("nickname", model.Nickname).Warn("couldn't send verification email")
+	}
+
+	return nil
+}
+
+func (u UserUsecase) sendVerification(ctx context.Context, nickname string, email string) error {
+	token, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	expires := time.Now().Add(u.verificationTTL)
+	err = u.userRepository.CreateVerification(ctx, nickname, email, auth.HashToken(token), expires)
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: verificationSubject,
+		Body:    fmt.Sprintf(verificationBody, nickname, email, url.PathEscape(nickname), token, expires.Format(time.RFC1123)),
+	})
+}
+
+func (u UserUsecase) Get(ctx context.Context, nickname string) (models.User, error) {
+	return u.userRepository.Get(ctx, nickname)
+}
+
+func (u UserUsecase) GetUsersWithNicknameAndEmail(ctx context.Context, nickname, email string) ([]models.User, error) {
+	return u.userRepository.GetUsersWithNicknameAndEmail(ctx, nickname, email)
+}
+
+// Update keeps the old email until the new one is confirmed when verification is on.
+// The answer then carries the requested address in PendingEmail.
+func (u UserUsecase) Update(ctx context.Context, model models.User) (models.User, error) {
+	if u.mailer == nil || model.Email == nil {
+		return u.userRepository.Update(ctx, model)
+	}
+
+	current, err := u.userRepository.Get(ctx, model.Nickname)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if strings.EqualFold(*current.Email, *model.Email) {
+		return u.userRepository.Update(ctx, model)
+	}
+
+	email := *model.Email
+	owner, err := u.userRepository.GetUserNicknameWithEmail(ctx, email)
+	if err == nil {
+		return models.User{}, fmt.Errorf("this email is already registered by user '%v': %w", owner, user.ErrDataConflict)
+	}
+	if !errors.Is(err, user.ErrUserDoesntExists) {
+		return models.User{}, err
+	}
+
+	model.Email = nil
+	updated, err := u.userRepository.Update(ctx, model)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = u.sendVerification(ctx, updated.Nickname, email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	updated.PendingEmail = &email
+	return updated, nil
+}
+
+func (u UserUsecase) CheckIfUserExists(ctx context.Context, nickname string) (string, error) {
+	return u.userRepository.CheckIfUserExists(ctx, nickname)
+}
+
+func (u UserUsecase) GetUserNicknameWithEmail(ctx context.Context, email string) (string, error) {
+	return u.userRepository.GetUserNicknameWithEmail(ctx, email)
+}
+
+func (u UserUsecase) GetUserIDByNickname(ctx context.Context, nickname string) (int, error) {
+	return u.userRepository.GetUserIDByNickname(ctx, nickname)
+}
+
+// Delete returns the tombstone profile in anonymize mode and an empty user after a hard delete.
+func (u UserUsecase) Delete(ctx context.Context, nickname string, mode string) (models.User, error) {
+	// Administrators remove spam accounts, which their owners won't.
+	err := u.permissions.RequireSelf(ctx, nickname)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	switch mode {
+	case user.DeleteModeHard:
+		return models.User{}, u.userRepository.Delete(ctx, nickname)
+	case user.DeleteModeAnonymize:
+		return u.userRepository.Anonymize(ctx, nickname)
+	default:
+		return models.User{}, fmt.Errorf("mode '%v' is neither '%v' nor '%v': %w", mode, user.DeleteModeHard, user.DeleteModeAnonymize, apperror.ErrInvalidParam)
+	}
+}
+
+func (u UserUsecase) Rename(ctx context.Context, from string, to string) (models.User, error) {
+	if !validNickname(to) {
+		return models.User{}, fmt.Errorf("can't rename '%v' to '%v': %w", from, to, user.ErrInvalidNickname)
+	}
+	if reservedNickname(to) {
+		return models.User{}, fmt.Errorf("can't rename '%v' to '%v': %w", from, to, user.ErrReservedNickname)
+	}
+
+	return u.userRepository.Rename(ctx, from, to)
+}
+
+func validNickname(nickname string) bool {
+	return nickname != "" && !strings.ContainsAny(nickname, "/ \t\n")
+}
+
+// reservedNickname tells nicknames of anonymized users, which are case-insensitive like the rest.
+func reservedNickname(nickname string) bool {
+	return strings.HasPrefix(strings.ToLower(nickname), user.TombstonePrefix)
+}
+
+func (u UserUsecase) ResolveRenamed(ctx context.Context, nickname string) (string, error) {
+	if u.redirectPeriod == 0 {
+		return "", fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
 	}
+
+	return u.userRepository.GetRenamed(ctx, nickname, time.Now().Add(-u.redirectPeriod))
 }
 
-func (u UserUsecase) Create(model models.User) error {
-	return u.userRepository.Create(model)
+func (u UserUsecase) Search(ctx context.Context, params user.SearchParams) ([]models.User, error) {
+	switch params.Match {
+	case "":
+		params.Match = user.MatchPrefix
+	case user.MatchPrefix:
+	case user.MatchFuzzy:
+		if params.Query == "" {
+			return nil, fmt.Errorf("fuzzy search needs a query: %w", apperror.ErrInvalidParam)
+		}
+	default:
+		return nil, fmt.Errorf("match '%v' is neither '%v' nor '%v': %w", params.Match, user.MatchPrefix, user.MatchFuzzy, apperror.ErrInvalidParam)
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultSearchLimit
+	}
+	if params.Limit < 0 || params.Limit > maxSearchLimit {
+		return nil, fmt.Errorf("limit %v is not between 1 and %v: %w", params.Limit, maxSearchLimit, apperror.ErrInvalidParam)
+	}
+
+	// Like unknown forums, private forums find nobody for non-members.
+	if params.Forum != "" {
+		err := u.permissions.RequireReader(ctx, params.Forum)
+		if errors.Is(err, permission.ErrPrivateForum) {
+			return []models.User{}, nil
+		}
+		if err != nil && !errors.Is(err, forum.ErrForumDoesntExists) {
+			return nil, err
+		}
+	}
+
+	return u.userRepository.Search(ctx, params)
 }
 
-func (u UserUsecase) Get(nickname string) (models.User, error) {
-	return u.userRepository.Get(nickname)
+func (u UserUsecase) RecomputeReputation(ctx context.Context) (int64, error) {
+	return u.userRepository.RecomputeReputation(ctx)
 }
 
-func (u UserUsecase) GetUsersWithNicknameAndEmail(nickname, email string) ([]models.User, error) {
-	return u.userRepository.GetUsersWithNicknameAndEmail(nickname, email)
+func (u UserUsecase) ConfirmEmail(ctx context.Context, nickname string, token string) (models.User, error) {
+	return u.userRepository.ConfirmEmail(ctx, nickname, auth.HashToken(token))
 }
 
-func (u UserUsecase) Update(model models.User) (models.User, error) {
-	return u.userRepository.Update(model)
+func (u UserUsecase) ResendVerification(ctx context.Context, nickname string) error {
+	if u.mailer == nil {
+		return user.ErrVerificationDisabled
+	}
+
+	profile, err := u.userRepository.Get(ctx, nickname)
+	if err != nil {
+		return err
+	}
+
+	if profile.Verified {
+		return fmt.Errorf("'%v' of '%v': %w", *profile.Email, profile.Nickname, user.ErrAlreadyVerified)
+	}
+
+	return u.sendVerification(ctx, profile.Nickname, *profile.Email)
+}
+
+// checkPair fails unless both users exist and are different people.
+func (u UserUsecase) checkPair(ctx context.Context, nickname string, other string) error {
+	nickname, err := u.userRepository.CheckIfUserExists(ctx, nickname)
+	if err != nil {
+		return err
+	}
+
+	other, err = u.userRepository.CheckIfUserExists(ctx, other)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(nickname, other) {
+		return fmt.Errorf("'%v': %w", nickname, user.ErrIgnoreSelf)
+	}
+
+	return nil
+}
+
+func (u UserUsecase) Ignore(ctx context.Context, nickname string, other string) error {
+	err := u.checkPair(ctx, nickname, other)
+	if err != nil {
+		return err
+	}
+
+	return u.userRepository.Ignore(ctx, nickname, other)
 }
 
-func (u UserUsecase) CheckIfUserExists(nickname string) (string, error) {
-	return u.userRepository.CheckIfUserExists(nickname)
+func (u UserUsecase) Unignore(ctx context.Context, nickname string, other string) error {
+	err := u.checkPair(ctx, nickname, other)
+	if err != nil {
+		return err
+	}
+
+	return u.userRepository.Unignore(ctx, nickname, other)
 }
 
-func (u UserUsecase) GetUserNicknameWithEmail(email string) (string, error) {
-	return u.userRepository.GetUserNicknameWithEmail(email)
+func (u UserUsecase) Import(ctx context.Context, entries []models.ImportedUser) (models.ImportReport, error) {
+	err := u.permissions.RequireAdmin(ctx)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	report := models.ImportReport{Results: make([]models.ImportResult, len(entries))}
+	invalid := func(i int, err error) error {
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) {
+			return err
+		}
+
+		report.Invalid++
+		report.Results[i] = models.ImportResult{
+			Index:    i,
+			Line:     entries[i].Line,
+			Nickname: entries[i].Nickname,
+			Status:   user.ImportInvalid,
+			Code:     appErr.Code,
+			Message:  err.Error(),
+		}
+		return nil
+	}
+
+	users := make([]models.User, len(entries))
+	valid := make([]bool, len(entries))
+	for i, entry := range entries {
+		switch {
+		case entry.Malformed != nil:
+			err = entry.Malformed
+		case !validNickname(entry.Nickname):
+			err = fmt.Errorf("can't import '%v': %w", entry.Nickname, user.ErrInvalidNickname)
+		case reservedNickname(entry.Nickname):
+			err = fmt.Errorf("can't import '%v': %w", entry.Nickname, user.ErrReservedNickname)
+		case entry.Email == nil || *entry.Email == "":
+			err = fmt.Errorf("can't import '%v': %w", entry.Nickname, user.ErrEmailRequired)
+		default:
+			users[i] = models.User{
+				Nickname: entry.Nickname,
+				Fullname: entry.Fullname,
+				Email:    entry.Email,
+				About:    entry.About,
+			}
+			valid[i] = true
+			continue
+		}
+
+		err = invalid(i, err)
+		if err != nil {
+			return models.ImportReport{}, err
+		}
+	}
+
+	hashErrs, err := hashPasswords(ctx, entries, users, valid)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	batch := make([]models.User, 0, importBatch)
+	indexes := make([]int, 0, importBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := u.userRepository.Import(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		for j, result := range results {
+			result.Index = indexes[j]
+			result.Line = entries[indexes[j]].Line
+			report.Results[indexes[j]] = result
+			if result.Status != user.ImportCreated {
+				report.Conflicted++
+				continue
+			}
+
+			report.Created++
+			if u.mailer == nil {
+				continue
+			}
+
+			// As with Create, a lost message can be sent again with ResendVerification.
+			err = u.sendVerification(ctx, batch[j].Nickname, *batch[j].Email)
+			if err != nil {
+				logrus.WithError(err).WithField("nickname", batch[j].Nickname).Warn("couldn't send verification email")
+			}
+		}
+
+		batch, indexes = batch[:0], indexes[:0]
+		return nil
+	}
+
+	for i := range entries {
+		if !valid[i] {
+			continue
+		}
+
+		if hashErrs[i] != nil {
+			err = invalid(i, fmt.Errorf("can't import '%v': %w", entries[i].Nickname, hashErrs[i]))
+			if err != nil {
+				return models.ImportReport{}, err
+			}
+			continue
+		}
+
+		batch = append(batch, users[i])
+		indexes = append(indexes, i)
+		if len(batch) == importBatch {
+			err = flush()
+			if err != nil {
+				return models.ImportReport{}, err
+			}
+		}
+	}
+
+	err = flush()
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	return report, nil
 }
 
-func (u UserUsecase) GetUserIDByNickname(nickname string) (int, error) {
-	return u.userRepository.GetUserIDByNickname(nickname)
+// hashPasswords hashes the passwords of the valid entries into users on every CPU,
+// since bcrypt is slow on purpose. Entries without a password are left without one.
+func hashPasswords(ctx context.Context, entries []models.ImportedUser, users []models.User, valid []bool) ([]error, error) {
+	errs := make([]error, len(entries))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hash, err := auth.HashPassword(entries[i].Password)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				users[i].PasswordHash = &hash
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range entries {
+		if !valid[i] || entries[i].Password == "" {
+			continue
+		}
+
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	return errs, err
 }
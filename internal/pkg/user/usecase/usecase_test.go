@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/permission"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+// admin lets every administrative call through.
+type admin struct {
+	permission.Usecase
+}
+
+func (admin) RequireAdmin(ctx context.Context) error {
+	return nil
+}
+
+// A tombstone nickname taken by someone else would make the rename in Anonymize
+// conflict, leaving the user with that id impossible to anonymize.
+func TestTombstoneNicknamesAreReserved(t *testing.T) {
+	ctx := context.Background()
+	usecase := New(newMemoryUsers(), 0, nil, time.Hour, admin{}).(UserUsecase)
+	email := "bob@example.com"
+
+	for _, nickname := range []string{"deleted.7", "Deleted.7", "deleted.bob"} {
+		err := usecase.Create(ctx, models.User{Nickname: nickname, Email: &email}, "")
+		if !errors.Is(err, user.ErrReservedNickname) {
+			t.Errorf("Create(%q) error = %v, want %v", nickname, err, user.ErrReservedNickname)
+		}
+
+		_, err = usecase.Rename(ctx, "bob", nickname)
+		if !errors.Is(err, user.ErrReservedNickname) {
+			t.Errorf("Rename(bob, %q) error = %v, want %v", nickname, err, user.ErrReservedNickname)
+		}
+
+		report, err := usecase.Import(ctx, []models.ImportedUser{{Nickname: nickname, Email: &email}})
+		if err != nil {
+			t.Fatalf("Import(%q) error = %v", nickname, err)
+		}
+		if report.Invalid != 1 || report.Results[0].Code != user.ErrReservedNickname.Code {
+			t.Errorf("Import(%q) = %+v, want it invalid with %v", nickname, report, user.ErrReservedNickname.Code)
+		}
+	}
+
+	err := usecase.Create(ctx, models.User{Nickname: "undeleted.7", Email: &email}, "")
+	if err != nil {
+		t.Errorf("Create(undeleted.7) error = %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aanufriev/forum/internal/pkg/mail"
+	"github.com/aanufriev/forum/internal/pkg/models"
+	"github.com/aanufriev/forum/internal/pkg/user"
+)
+
+type verification struct {
+	email   string
+	hash    []byte
+	expires time.Time
+}
+
+// memoryUsers keeps just what the verification flow touches; the rest of
+// user.Repository panics if called.
+type memoryUsers struct {
+	user.Repository
+	users   map[string]*models.User
+	pending map[string][]verification
+	// skew moves the clock expiry is checked against.
+	skew time.Duration
+}
+
+func newMemoryUsers() *memoryUsers {
+	return &memoryUsers{
+		users:   make(map[string]*models.User),
+		pending: make(map[string][]verification),
+	}
+}
+
+func (m *memoryUsers) Create(ctx context.Context, model models.User) error {
+	m.users[strings.ToLower(model.Nickname)] = &model
+	return nil
+}
+
+func (m *memoryUsers) Import(ctx context.Context, users []models.User) ([]models.ImportResult, error) {
+	results := make([]models.ImportResult, 0, len(users))
+	for _, model := range users {
+		status := user.ImportConflict
+		if _, ok := m.users[strings.ToLower(model.Nickname)]; !ok {
+			m.Create(ctx, model)
+			status = user.ImportCreated
+		}
+		results = append(results, models.ImportResult{Nickname: model.Nickname, Status: status})
+	}
+
+	return results, nil
+}
+
+func (m *memoryUsers) Get(ctx context.Context, nickname string) (models.User, error) {
+	model, ok := m.users[strings.ToLower(nickname)]
+	if !ok {
+		return models.User{}, fmt.Errorf("can't find user with nickname '%v': %w", nickname, user.ErrUserDoesntExists)
+	}
+
+	return *model, nil
+}
+
+func (m *memoryUsers) Update(ctx context.Context, model models.User) (models.User, error) {
+	current, ok := m.users[strings.ToLower(model.Nickname)]
+	if !ok {
+		return models.User{}, fmt.Errorf("can't find user with nickname '%v': %w", model.Nickname, user.ErrUserDoesntExists)
+	}
+
+	if model.Fullname != nil {
+		current.Fullname = model.Fullname
+	}
+	if model.Email != nil {
+		current.Email = model.Email
+		current.Verified = false
+	}
+	if model.About != nil {
+		current.About = model.About
+	}
+
+	return *current, nil
+}
+
+func (m *memoryUsers) GetUserNicknameWithEmail(ctx context.Context, email string) (string, error) {
+	for _, model := range m.users {
+		if strings.EqualFold(*model.Email, email) {
+			return model.Nickname, nil
+		}
+	}
+
+	return "", fmt.Errorf("can't find user with email '%v': %w", email, user.ErrUserDoesntExists)
+}
+
+func (m *memoryUsers) CreateVerification(ctx context.Context, nickname string, email string, tokenHash []byte, expires time.Time) error {
+	m.pending[strings.ToLower(nickname)] = []verification{{email: email, hash: tokenHash, expires: expires}}
+	return nil
+}
+
+func (m *memoryUsers) ConfirmEmail(ctx context.Context, nickname string, tokenHash []byte) (models.User, error) {
+	key := strings.ToLower(nickname)
+	for _, v := range m.pending[key] {
+		if bytes.Equal(v.hash, tokenHash) && time.Now().Add(m.skew).Before(v.expires) {
+			model := m.users[key]
+			model.Email = &v.email
+			model.Verified = true
+			delete(m.pending, key)
+			return *model, nil
+		}
+	}
+
+	return models.User{}, fmt.Errorf("can't confirm email of '%v': %w", nickname, user.ErrInvalidVerification)
+}
+
+var tokenPattern = regexp.MustCompile(`\{"token": "([A-Za-z0-9_-]+)"\}`)
+
+// lastToken reads the verification token of the newest message in the outbox,
+// checking that it went to the address.
+func lastToken(t *testing.T, dir string, to string) string {
+	t.Helper()
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("outbox is empty")
+	}
+	// Names start with the delivery time in nanoseconds.
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	message, err := ioutil.ReadFile(filepath.Join(dir, "new", files[len(files)-1].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(message, []byte("To: "+to+"\r\n")) {
+		t.Fatalf("last message isn't to %v:\n%s", to, message)
+	}
+
+	match := tokenPattern.FindSubmatch(message)
+	if match == nil {
+		t.Fatalf("no token in the last message:\n%s", message)
+	}
+
+	return string(match[1])
+}
+
+func newVerifyingUsecase(t *testing.T) (UserUsecase, *memoryUsers, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	outbox, err := mail.NewOutbox(dir, "forum@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := newMemoryUsers()
+	usecase := New(repository, 0, outbox, time.Hour, admin{}).(UserUsecase)
+
+	return usecase, repository, dir
+}
+
+func signUp(t *testing.T, usecase UserUsecase, nickname string, email string) {
+	t.Helper()
+
+	err := usecase.Create(context.Background(), models.User{Nickname: nickname, Email: &email}, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
+
+func TestVerificationFlow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("signup", func(t *testing.T) {
+		usecase, _, dir := newVerifyingUsecase(t)
+		signUp(t, usecase, "bob", "bob@example.com")
+
+		profile, err := usecase.Get(ctx, "bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profile.Verified {
+			t.Fatal("new account is verified before confirming")
+		}
+
+		_, err = usecase.ConfirmEmail(ctx, "bob", "wrong")
+		if !errors.Is(err, user.ErrInvalidVerification) {
+			t.Fatalf("ConfirmEmail() with a wrong token error = %v, want %v", err, user.ErrInvalidVerification)
+		}
+
+		confirmed, err := usecase.ConfirmEmail(ctx, "bob", lastToken(t, dir, "bob@example.com"))
+		if err != nil {
+			t.Fatalf("ConfirmEmail() error = %v", err)
+		}
+		if !confirmed.Verified || *confirmed.Email != "bob@example.com" {
+			t.Fatalf("ConfirmEmail() = %+v, want bob@example.com verified", confirmed)
+		}
+
+		err = usecase.ResendVerification(ctx, "bob")
+		if !errors.Is(err, user.ErrAlreadyVerified) {
+			t.Fatalf("ResendVerification() error = %v, want %v", err, user.ErrAlreadyVerified)
+		}
+	})
+
+	t.Run("expired token and resend", func(t *testing.T) {
+		usecase, repository, dir := newVerifyingUsecase(t)
+		signUp(t, usecase, "bob", "bob@example.com")
+		expired := lastToken(t, dir, "bob@example.com")
+
+		repository.skew = 2 * time.Hour
+		_, err := usecase.ConfirmEmail(ctx, "bob", expired)
+		if !errors.Is(err, user.ErrInvalidVerification) {
+			t.Fatalf("ConfirmEmail() with an expired token error = %v, want %v", err, user.ErrInvalidVerification)
+		}
+
+		repository.skew = 0
+		err = usecase.ResendVerification(ctx, "bob")
+		if err != nil {
+			t.Fatalf("ResendVerification() error = %v", err)
+		}
+
+		fresh := lastToken(t, dir, "bob@example.com")
+		if fresh == expired {
+			t.Fatal("resent token is the expired one")
+		}
+
+		_, err = usecase.ConfirmEmail(ctx, "bob", expired)
+		if !errors.Is(err, user.ErrInvalidVerification) {
+			t.Fatalf("ConfirmEmail() with a replaced token error = %v, want %v", err, user.ErrInvalidVerification)
+		}
+
+		confirmed, err := usecase.ConfirmEmail(ctx, "bob", fresh)
+		if err != nil {
+			t.Fatalf("ConfirmEmail() error = %v", err)
+		}
+		if !confirmed.Verified {
+			t.Fatal("resent token didn't verify the address")
+		}
+	})
+
+	t.Run("import", func(t *testing.T) {
+		usecase, _, dir := newVerifyingUsecase(t)
+		email := "alice@example.com"
+		report, err := usecase.Import(ctx, []models.ImportedUser{{Nickname: "alice", Email: &email}})
+		if err != nil || report.Created != 1 {
+			t.Fatalf("Import() = %+v, %v, want alice created", report, err)
+		}
+
+		profile, err := usecase.Get(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profile.Verified {
+			t.Fatal("imported account is verified before confirming")
+		}
+
+		confirmed, err := usecase.ConfirmEmail(ctx, "alice", lastToken(t, dir, email))
+		if err != nil || !confirmed.Verified {
+			t.Fatalf("ConfirmEmail() = %+v, %v, want alice verified", confirmed, err)
+		}
+	})
+
+	t.Run("email change", func(t *testing.T) {
+		usecase, _, dir := newVerifyingUsecase(t)
+		signUp(t, usecase, "bob", "bob@example.com")
+		_, err := usecase.ConfirmEmail(ctx, "bob", lastToken(t, dir, "bob@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		email := "robert@example.com"
+		updated, err := usecase.Update(ctx, models.User{Nickname: "bob", Email: &email})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if *updated.Email != "bob@example.com" || updated.PendingEmail == nil || *updated.PendingEmail != email {
+			t.Fatalf("Update() = email %v, pending %v, want bob@example.com pending %v", *updated.Email, updated.PendingEmail, email)
+		}
+
+		profile, err := usecase.Get(ctx, "bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *profile.Email != "bob@example.com" || !profile.Verified {
+			t.Fatalf("before confirming, profile = %v verified %v, want the old address verified", *profile.Email, profile.Verified)
+		}
+
+		confirmed, err := usecase.ConfirmEmail(ctx, "bob", lastToken(t, dir, email))
+		if err != nil {
+			t.Fatalf("ConfirmEmail() error = %v", err)
+		}
+		if *confirmed.Email != email || !confirmed.Verified {
+			t.Fatalf("ConfirmEmail() = %v verified %v, want %v verified", *confirmed.Email, confirmed.Verified, email)
+		}
+	})
+}